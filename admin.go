@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gernest/8x8/pkg/storage"
+	"github.com/gernest/8x8/pkg/xl"
+)
+
+// errNoServer is returned by fromServer when no server listens on the admin
+// socket.
+var errNoServer = errors.New("the server is not running")
+
+// errAdminInUse is returned by serveAdmin when another server answers on the
+// admin socket.
+var errAdminInUse = errors.New("another server is running")
+
+// errorTrailer carries the error of a response that failed after its body
+// started, the status code is already sent by then.
+const errorTrailer = "X-8x8-Error"
+
+// serveAdmin answers the backup and export commands on the unix socket at
+// path until ctx is done. Only the user running the server can connect.
+func serveAdmin(ctx context.Context, path string, store storage.Store) error {
+	l, err := listenAdmin(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/backup", func(w http.ResponseWriter, r *http.Request) {
+		d, ok := store.(*storage.DefaultStore)
+		if !ok {
+			http.Error(w, "only badger stores are backed up, use the tools of the database", http.StatusNotImplemented)
+			return
+		}
+		stream(w, "backup", func(w io.Writer) error {
+			return d.Backup(w)
+		})
+	})
+	mux.HandleFunc("/export", func(w http.ResponseWriter, r *http.Request) {
+		stream(w, "export", func(w io.Writer) error {
+			return storage.Export(r.Context(), store, w)
+		})
+	})
+	srv := &http.Server{Handler: mux}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	err = srv.Serve(l)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// listenAdmin listens on the unix socket at path, replacing the socket a
+// server that stopped left behind.
+func listenAdmin(path string) (net.Listener, error) {
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return nil, fmt.Errorf("%w on %s", errAdminInUse, path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	// The socket is made in a directory only we can enter and moved in place
+	// once its mode is set, no one else can connect in between.
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".admin")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	tmp := filepath.Join(dir, filepath.Base(path))
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tmp, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// stream writes the output of fn as the body of the response, reporting an
+// error in the trailer.
+func stream(w http.ResponseWriter, name string, fn func(io.Writer) error) {
+	w.Header().Set("Trailer", errorTrailer)
+	if err := fn(w); err != nil {
+		xl.Error(err, "failed admin "+name)
+		w.Header().Set(errorTrailer, err.Error())
+	}
+}
+
+// fromServer copies the response of the server listening on the admin socket
+// for path to w.
+func fromServer(socket, path string, w io.Writer) error {
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	res, err := client.Get("http://8x8" + path)
+	if err != nil {
+		var op *net.OpError
+		if errors.As(err, &op) && op.Op == "dial" {
+			return fmt.Errorf("%w: %v", errNoServer, err)
+		}
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("%s: %s", res.Status, msg)
+	}
+	if _, err := io.Copy(w, res.Body); err != nil {
+		return err
+	}
+	if msg := res.Trailer.Get(errorTrailer); msg != "" {
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gernest/8x8/pkg/storage"
+	"github.com/urfave/cli"
+)
+
+var backupCommand = cli.Command{
+	Name:  "backup",
+	Usage: "writes a backup of the badger database, also while the server runs",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the backup to",
+		},
+	},
+	Action: backup,
+}
+
+var restoreCommand = cli.Command{
+	Name:  "restore",
+	Usage: "loads a backup into an empty badger database, with the server stopped",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "in",
+			Usage: "backup file written by backup",
+		},
+	},
+	Action: restore,
+}
+
+var exportCommand = cli.Command{
+	Name:  "export",
+	Usage: "dumps users and games for inspection",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Value: "jsonl",
+			Usage: "output format, jsonl writes a JSON object per line",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write to instead of the standard output",
+		},
+	},
+	Action: export,
+}
+
+// withStore calls fn with the store selected by the global flags. The server
+// must not be running when it is a badger database.
+func withStore(ctx *cli.Context, fn func(storage.Store) error) error {
+	s, db, err := storage.Open(context.Background(), ctx.GlobalString("storage"), ctx.GlobalString("source"))
+	if err != nil {
+		return err
+	}
+	if err := fn(s); err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// badgerStore returns s as a badger store.
+func badgerStore(s storage.Store) (*storage.DefaultStore, error) {
+	d, ok := s.(*storage.DefaultStore)
+	if !ok {
+		return nil, errors.New("only badger stores are backed up, use the tools of the database")
+	}
+	return d, nil
+}
+
+// writeFile writes name with fn, removing it when fn fails.
+func writeFile(name string, fn func(io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		os.Remove(name)
+		return err
+	}
+	return f.Close()
+}
+
+func backup(ctx *cli.Context) error {
+	name := ctx.String("out")
+	if name == "" {
+		return errors.New("no backup file given, use --out")
+	}
+	return writeFile(name, func(w io.Writer) error {
+		// A running server holds the database, it takes the backup itself.
+		err := fromServer(ctx.GlobalString("admin"), "/backup", w)
+		if !errors.Is(err, errNoServer) {
+			return err
+		}
+		return withStore(ctx, func(s storage.Store) error {
+			d, err := badgerStore(s)
+			if err != nil {
+				return err
+			}
+			return d.Backup(w)
+		})
+	})
+}
+
+func restore(ctx *cli.Context) error {
+	name := ctx.String("in")
+	if name == "" {
+		return errors.New("no backup file given, use --in")
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return withStore(ctx, func(s storage.Store) error {
+		d, err := badgerStore(s)
+		if err != nil {
+			return err
+		}
+		return d.Restore(f)
+	})
+}
+
+func export(ctx *cli.Context) error {
+	if f := ctx.String("format"); f != "jsonl" {
+		return fmt.Errorf("unknown export format %q", f)
+	}
+	run := func(w io.Writer) error {
+		err := fromServer(ctx.GlobalString("admin"), "/export", w)
+		if !errors.Is(err, errNoServer) {
+			return err
+		}
+		return withStore(ctx, func(s storage.Store) error {
+			return storage.Export(context.Background(), s, w)
+		})
+	}
+	if name := ctx.String("out"); name != "" {
+		return writeFile(name, run)
+	}
+	return run(ctx.App.Writer)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/pdn"
+	"github.com/urfave/cli"
+)
+
+var bookCommand = cli.Command{
+	Name:  "book",
+	Usage: "builds and looks up opening books",
+	Subcommands: []cli.Command{
+		{
+			Name:      "build",
+			Usage:     "compiles an opening book from game records",
+			ArgsUsage: "games.pdn...",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "out",
+					Value: "book.txt",
+					Usage: "file to write the book to",
+				},
+				cli.IntFlag{
+					Name:  "plies",
+					Value: 16,
+					Usage: "number of moves of each game to take",
+				},
+				cli.StringFlag{
+					Name:  "names",
+					Usage: "file of opening names, lines of moves from the start followed by a name",
+				},
+			},
+			Action: buildBook,
+		},
+		{
+			Name:      "probe",
+			Usage:     "prints the book moves after a sequence of moves",
+			ArgsUsage: "[move...]",
+			Flags:     []cli.Flag{bookFlag},
+			Action:    probeBook,
+		},
+	},
+}
+
+var bookFlag = cli.StringFlag{
+	Name:  "book",
+	Value: "book.txt",
+	Usage: "opening book file written by book build",
+}
+
+func buildBook(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return errors.New("no game files given")
+	}
+	var games []*pdn.Game
+	for _, name := range ctx.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		gs, err := pdn.Parse(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		games = append(games, gs...)
+	}
+	bk, err := pdn.Book(games, ctx.Int("plies"))
+	if err != nil {
+		return err
+	}
+	if name := ctx.String("names"); name != "" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = bk.ReadNames(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	f, err := os.Create(ctx.String("out"))
+	if err != nil {
+		return err
+	}
+	if _, err := bk.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	fmt.Fprintf(ctx.App.Writer, "%d games, %d positions\n", len(games), bk.Len())
+	return f.Close()
+}
+
+// loadBook reads the opening book file called name.
+func loadBook(name string) (*check.Book, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	bk, err := check.ReadBook(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return bk, nil
+}
+
+func probeBook(ctx *cli.Context) error {
+	bk, err := loadBook(ctx.String("book"))
+	if err != nil {
+		return err
+	}
+	g := bk.Variant.NewGame()
+	for _, s := range ctx.Args() {
+		m, err := check.ParseMove(s)
+		if err != nil {
+			return err
+		}
+		if err := g.Apply(m); err != nil {
+			return err
+		}
+	}
+	out := ctx.App.Writer
+	if name := bk.Opening(g); name != "" {
+		fmt.Fprintf(out, "opening: %s\n", name)
+	}
+	fmt.Fprintln(out, bookMoves(bk, g))
+	return nil
+}
+
+// bookMoves lists the book moves of the position of g with their weights.
+func bookMoves(bk *check.Book, g *check.Game) string {
+	moves := bk.Moves(g.Board())
+	if len(moves) == 0 {
+		return "out of book"
+	}
+	ls := make([]string, len(moves))
+	for i, m := range moves {
+		ls[i] = fmt.Sprintf("%s (%d)", check.Notation(m.Move), m.Weight)
+	}
+	return strings.Join(ls, " ")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/urfave/cli"
+)
+
+var evalCommand = cli.Command{
+	Name:  "eval",
+	Usage: "prints the evaluation of a position feature by feature",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "fen",
+			Usage: "position to evaluate, the starting position when empty",
+		},
+		variantFlag,
+		weightsFlag,
+	},
+	Action: eval,
+}
+
+var weightsFlag = cli.StringFlag{
+	Name:  "weights",
+	Usage: "file of evaluation weights, the defaults of the variant when empty",
+}
+
+// weights returns the evaluation weights given by the weights flag.
+func weights(ctx *cli.Context, b *check.Board) (check.Weights, error) {
+	v := check.LookupVariant(b.Variant)
+	if name := ctx.String("weights"); name != "" {
+		return v.LoadWeights(name)
+	}
+	return v.DefaultWeights(), nil
+}
+
+func eval(ctx *cli.Context) error {
+	b, err := board(ctx)
+	if err != nil {
+		return err
+	}
+	w, err := weights(ctx, b)
+	if err != nil {
+		return err
+	}
+	t := b.Features()
+	out := ctx.App.Writer
+	fmt.Fprintf(out, "%s, %v to move\n\n", b.FEN(), check.Player(b.PlayertTurn))
+	fmt.Fprintf(out, "%-10s %6s %6s %6s\n", "feature", "value", "weight", "score")
+	for f, x := range t {
+		fmt.Fprintf(out, "%-10s %6d %6d %6d\n", check.Feature(f), x, w[f], x*w[f])
+	}
+	fmt.Fprintf(out, "%-10s %20d\n", "total", w.Evaluate(t))
+	return nil
+}
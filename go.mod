@@ -15,4 +15,5 @@ require (
 	go.uber.org/zap v1.16.0
 	golang.org/x/crypto v0.0.0-20210421170649-83a5a9bb288b // indirect
 	golang.org/x/oauth2 v0.0.0-20210427180440-81ed05c6b58c
+	modernc.org/sqlite v1.20.3
 )
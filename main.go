@@ -13,6 +13,7 @@ import (
 	"github.com/caddyserver/certmagic"
 	"github.com/gernest/8x8/pkg/auth"
 	"github.com/gernest/8x8/pkg/mw"
+	"github.com/gernest/8x8/pkg/storage"
 	"github.com/gernest/8x8/pkg/xl"
 	"github.com/gernest/8x8/templates"
 	"github.com/gorilla/mux"
@@ -42,6 +43,35 @@ func main() {
 			Usage:  "installs systemd unit files and sets up 8x8",
 			Action: install,
 		},
+		perftCommand,
+		searchCommand,
+		evalCommand,
+		tuneCommand,
+		tablebaseCommand,
+		bookCommand,
+		playCommand,
+		backupCommand,
+		restoreCommand,
+		exportCommand,
+	}
+	a.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:   "storage",
+			Value:  "badger",
+			Usage:  "where to keep users and games: badger, or a database/sql driver such as sqlite",
+			EnvVar: "8x8_STORAGE",
+		},
+		cli.StringFlag{
+			Name:   "source",
+			Value:  filepath.Join(DataDirectory, "db"),
+			Usage:  "the badger directory or the database to open",
+			EnvVar: "8x8_STORAGE_SOURCE",
+		},
+		cli.StringFlag{
+			Name:  "admin",
+			Value: filepath.Join(DataDirectory, "admin.sock"),
+			Usage: "unix socket the server answers backup and export on",
+		},
 	}
 	a.Action = run
 	if err := a.Run(os.Args); err != nil {
@@ -52,14 +82,24 @@ func main() {
 	}
 }
 
-func run(_ *cli.Context) error {
+func run(c *cli.Context) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	tpl, err := template.ParseFS(templates.Files, "*/*.html")
 	if err != nil {
 		return err
 	}
-	m := mw.New()
+	store, db, err := storage.Open(ctx, c.String("storage"), c.String("source"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	go func() {
+		if err := serveAdmin(ctx, c.String("admin"), store); err != nil {
+			xl.Error(err, "exit admin server")
+		}
+	}()
+	m := mw.New().Append(mw.Store(store))
 	mu := mux.NewRouter()
 	mu.HandleFunc("/", func(rw http.ResponseWriter, r *http.Request) {
 		err := tpl.ExecuteTemplate(rw, "index.html", map[string]interface{}{})
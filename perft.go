@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/urfave/cli"
+)
+
+var perftCommand = cli.Command{
+	Name:  "perft",
+	Usage: "counts the move sequences of a given depth to verify move generation",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "depth",
+			Value: 6,
+			Usage: "number of moves to look ahead",
+		},
+		cli.StringFlag{
+			Name:  "fen",
+			Usage: "position to count from, the starting position when empty",
+		},
+		variantFlag,
+		cli.BoolFlag{
+			Name:  "divide",
+			Usage: "print the count below each legal move",
+		},
+	},
+	Action: perft,
+}
+
+var variantFlag = cli.StringFlag{
+	Name:  "variant",
+	Value: check.English.Name,
+	Usage: "rules to play by",
+}
+
+// board returns the position given by the variant and fen flags.
+func board(ctx *cli.Context) (*check.Board, error) {
+	v := check.LookupVariant(ctx.String("variant"))
+	if v == nil {
+		return nil, fmt.Errorf("unknown variant %q", ctx.String("variant"))
+	}
+	if fen := ctx.String("fen"); fen != "" {
+		return v.ParseFEN(fen)
+	}
+	return v.NewBoard(), nil
+}
+
+func perft(ctx *cli.Context) error {
+	b, err := board(ctx)
+	if err != nil {
+		return err
+	}
+	depth := ctx.Int("depth")
+	start := time.Now()
+	var nodes uint64
+	if ctx.Bool("divide") {
+		for _, c := range check.Divide(b, depth) {
+			fmt.Fprintf(ctx.App.Writer, "%s %d\n", check.Notation(c.Move), c.Nodes)
+			nodes += c.Nodes
+		}
+	} else {
+		nodes = check.Perft(b, depth)
+	}
+	elapsed := time.Since(start)
+	fmt.Fprintf(ctx.App.Writer, "depth %d nodes %d time %v (%.0f nodes/s)\n",
+		depth, nodes, elapsed.Round(time.Millisecond), float64(nodes)/elapsed.Seconds())
+	return nil
+}
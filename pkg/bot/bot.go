@@ -0,0 +1,154 @@
+// Package bot has computer opponents for beginners, from a random mover up to
+// a few moves of lookahead. They play straight on the move generator of
+// pkg/check; pkg/engine is the strong opponent.
+package bot
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+)
+
+var (
+	// ErrNoMoves is returned when a bot is asked to move without legal moves.
+	ErrNoMoves = errors.New("bot: no legal moves")
+
+	// ErrUnknownBot is returned when looking up a bot that does not exist.
+	ErrUnknownBot = errors.New("bot: unknown bot")
+)
+
+// Bot is a computer opponent of a fixed strength.
+type Bot struct {
+	Name        string
+	Description string
+	// Rating is the nominal strength of the bot on the Elo scale, used to
+	// order the ladder.
+	Rating int
+
+	// depth is how many moves the bot looks ahead, evaluating the positions
+	// it reaches with eval. A bot without eval moves at random.
+	depth int
+	eval  evaluator
+	// blunders is the chance the bot plays a random move instead of its
+	// best one.
+	blunders float64
+}
+
+// Ladder are the bots from the weakest to the strongest.
+var Ladder = []*Bot{
+	{
+		Name:        "rookie",
+		Description: "plays any legal move",
+		Rating:      400,
+	},
+	{
+		Name:        "grabber",
+		Description: "takes as much as it can, never looks further",
+		Rating:      600,
+		depth:       1,
+		eval:        material,
+	},
+	{
+		Name:        "racer",
+		Description: "rushes its men to be crowned",
+		Rating:      700,
+		depth:       2,
+		eval:        kingRush,
+		blunders:    0.1,
+	},
+	{
+		Name:        "trader",
+		Description: "swaps pieces whenever it can",
+		Rating:      750,
+		depth:       2,
+		eval:        trades,
+		blunders:    0.1,
+	},
+	{
+		Name:        "turtle",
+		Description: "guards its back row and waits",
+		Rating:      800,
+		depth:       2,
+		eval:        defensive,
+		blunders:    0.1,
+	},
+	{
+		Name:        "thinker",
+		Description: "looks three moves ahead but often overlooks something",
+		Rating:      1000,
+		depth:       3,
+		eval:        material,
+		blunders:    0.2,
+	},
+	{
+		Name:        "scholar",
+		Description: "looks four moves ahead and rarely blunders",
+		Rating:      1200,
+		depth:       4,
+		eval:        material,
+		blunders:    0.05,
+	},
+}
+
+func init() {
+	sort.SliceStable(Ladder, func(i, j int) bool {
+		return Ladder[i].Rating < Ladder[j].Rating
+	})
+}
+
+// Lookup returns the bot called name.
+func Lookup(name string) (*Bot, error) {
+	for _, b := range Ladder {
+		if strings.EqualFold(b.Name, name) {
+			return b, nil
+		}
+	}
+	return nil, ErrUnknownBot
+}
+
+// Move returns the move the bot chooses in g, using r for its random
+// choices.
+func (b *Bot) Move(g *check.Game, r *rand.Rand) (*models.Move, error) {
+	moves := g.LegalMoves()
+	if len(moves) == 0 {
+		return nil, ErrNoMoves
+	}
+	if b.eval == nil || len(moves) == 1 || r.Float64() < b.blunders {
+		return moves[r.Intn(len(moves))], nil
+	}
+	v := g.Variant()
+	me := g.Turn()
+	var best []*models.Move
+	bestScore := 0
+	for _, m := range moves {
+		next := g.Board().Clone()
+		next.Play(m)
+		// Moves worse than the best so far need not be scored exactly, but
+		// those as good are, to choose between them.
+		alpha := -win
+		if len(best) > 0 {
+			alpha = bestScore - 1
+		}
+		score := search(v, next, b.depth-1, me, b.eval, alpha, win)
+		switch {
+		case len(best) == 0 || score > bestScore:
+			best, bestScore = []*models.Move{m}, score
+		case score == bestScore:
+			best = append(best, m)
+		}
+	}
+	return best[r.Intn(len(best))], nil
+}
+
+// Play applies the move the bot chooses in g and returns it.
+func (b *Bot) Play(g *check.Game, r *rand.Rand) (*models.Move, error) {
+	m, err := b.Move(g, r)
+	if err != nil {
+		return nil, err
+	}
+	return m, g.Apply(m)
+}
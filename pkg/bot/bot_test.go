@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/pdn"
+)
+
+func TestLadder(t *testing.T) {
+	names := make(map[string]bool)
+	for i, b := range Ladder {
+		if names[b.Name] {
+			t.Errorf("%s: duplicate name", b.Name)
+		}
+		names[b.Name] = true
+		if i > 0 && b.Rating < Ladder[i-1].Rating {
+			t.Errorf("%s: expected the ladder to be sorted by rating", b.Name)
+		}
+	}
+	if b, err := Lookup("Scholar"); err != nil || b.Name != "scholar" {
+		t.Errorf("expected to find scholar got %v %v", b, err)
+	}
+	if _, err := Lookup("grandmaster"); !errors.Is(err, ErrUnknownBot) {
+		t.Errorf("expected an unknown bot got %v", err)
+	}
+}
+
+// TestBots has every bot play a game against itself in a few variants.
+func TestBots(t *testing.T) {
+	for _, v := range []*check.Variant{check.English, check.Russian, check.Turkish} {
+		for _, b := range Ladder {
+			t.Run(v.Name+"/"+b.Name, func(t *testing.T) {
+				r := rand.New(rand.NewSource(1))
+				g := v.NewGame()
+				for ply := 0; ply < 40 && !g.Over(); ply++ {
+					if _, err := b.Play(g, r); err != nil {
+						t.Fatalf("ply %d: %v", ply, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestGrabber(t *testing.T) {
+	b, err := check.ParseFEN("B:W14,15,23:B10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grabber, _ := Lookup("grabber")
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		m, err := grabber.Move(check.NewGameFrom(b.Clone()), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(m.Path, []int32{10, 19, 26}) {
+			t.Fatalf("expected the double jump got %s", check.Notation(m))
+		}
+	}
+	b, _ = check.ParseFEN("B:W14:B")
+	if _, err := grabber.Move(check.NewGameFrom(b), r); !errors.Is(err, ErrNoMoves) {
+		t.Errorf("expected no moves got %v", err)
+	}
+}
+
+// TestStrength checks the top of the ladder beats the bottom.
+func TestStrength(t *testing.T) {
+	rookie, scholar := Ladder[0], Ladder[len(Ladder)-1]
+	wins := 0
+	for seed := int64(1); seed <= 4; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		g := check.English.NewGame()
+		for ply := 0; ply < 200 && !g.Over(); ply++ {
+			b := rookie
+			if g.Turn() == check.White {
+				b = scholar
+			}
+			if _, err := b.Play(g, r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if g.Result() == check.WhiteWins {
+			wins++
+		}
+	}
+	if wins < 3 {
+		t.Errorf("expected %s to beat %s got %d wins in 4 games", scholar.Name, rookie.Name, wins)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	m, err := NewMatch(check.English, "trader", check.White, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Reply(); !errors.Is(err, ErrNotBotsTurn) {
+		t.Errorf("expected black to move first got %v", err)
+	}
+	if err := m.Game.Apply(m.Game.LegalMoves()[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Reply(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Game.Turn() != check.Black {
+		t.Error("expected the bot to have moved")
+	}
+
+	g, err := pdn.ParseString(m.Record("alice").String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g[0].Tag("Black") != "alice" || g[0].Tag("WhiteElo") != "750" {
+		t.Errorf("expected the players in the tags got %v", g[0].Tags)
+	}
+	b, side, err := Recorded(g[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != m.Bot || side != check.White {
+		t.Errorf("expected %s playing white got %s playing %v", m.Bot.Name, b.Name, side)
+	}
+	if _, err := NewMatch(check.English, "nobody", check.White, 1); !errors.Is(err, ErrUnknownBot) {
+		t.Errorf("expected an unknown bot got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"github.com/gernest/8x8/pkg/check"
+)
+
+// evaluator scores b from p's point of view, higher is better for p.
+type evaluator func(v *check.Variant, b *check.Board, p check.Player) int
+
+// win is the score of a won position, above anything an evaluator returns.
+const win = 1 << 20
+
+// search returns the value of b for p after looking depth moves ahead, the
+// side to move picking its best move by eval. Values outside alpha and beta
+// are only known to be outside.
+func search(v *check.Variant, b *check.Board, depth int, p check.Player, eval evaluator, alpha, beta int) int {
+	moves := b.Moves()
+	turn := check.Player(b.PlayertTurn)
+	if len(moves) == 0 {
+		if turn == p {
+			return -win
+		}
+		return win
+	}
+	if depth <= 0 {
+		return eval(v, b, p)
+	}
+	for _, m := range moves {
+		next := b.Clone()
+		next.Play(m)
+		score := search(v, next, depth-1, p, eval, alpha, beta)
+		if turn == p && score > alpha {
+			alpha = score
+		}
+		if turn != p && score < beta {
+			beta = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	if turn == p {
+		return alpha
+	}
+	return beta
+}
+
+// Piece values, a man is worth 100.
+const (
+	man  = 100
+	king = 150
+)
+
+// material counts pieces, kings being worth more than men.
+func material(v *check.Variant, b *check.Board, p check.Player) int {
+	score := 0
+	for _, pc := range b.UncapturedPieces {
+		value := man
+		if pc.King {
+			value = king
+		}
+		if check.Player(pc.Player) != p {
+			value = -value
+		}
+		score += value
+	}
+	return score
+}
+
+// advance returns how many rows the man of p on square has moved up from
+// p's back row.
+func advance(v *check.Variant, p check.Player, square int32) int {
+	row, _ := v.Coordinates(square)
+	if p == check.Black {
+		return row
+	}
+	return v.Size - 1 - row
+}
+
+// kingRush likes men close to being crowned and kings even more.
+func kingRush(v *check.Variant, b *check.Board, p check.Player) int {
+	score := material(v, b, p)
+	for _, pc := range b.UncapturedPieces {
+		if check.Player(pc.Player) != p {
+			continue
+		}
+		if pc.King {
+			score += 50
+		} else {
+			score += 8 * advance(v, p, pc.Position)
+		}
+	}
+	return score
+}
+
+// trades likes an emptier board, so equal exchanges look like gains.
+func trades(v *check.Variant, b *check.Board, p check.Player) int {
+	return material(v, b, p) - 20*len(b.UncapturedPieces)
+}
+
+// defensive likes men that stay on their back row and stay home.
+func defensive(v *check.Variant, b *check.Board, p check.Player) int {
+	score := material(v, b, p)
+	for _, pc := range b.UncapturedPieces {
+		if check.Player(pc.Player) != p || pc.King {
+			continue
+		}
+		switch a := advance(v, p, pc.Position); a {
+		case 0:
+			score += 15
+		default:
+			score -= 3 * a
+		}
+	}
+	return score
+}
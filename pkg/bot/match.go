@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"errors"
+	"math/rand"
+	"strconv"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+	"github.com/gernest/8x8/pkg/pdn"
+)
+
+// ErrNotBotsTurn is returned when asking the bot of a match to move while it
+// is the other player's turn.
+var ErrNotBotsTurn = errors.New("bot: not the bot's turn")
+
+// Match is a game against a bot of the ladder.
+type Match struct {
+	Game *check.Game
+	Bot  *Bot
+	// Side is the colour the bot plays.
+	Side check.Player
+
+	rand *rand.Rand
+}
+
+// NewMatch starts a game of v against the bot called level playing side. The
+// bot's random choices are drawn from seed.
+func NewMatch(v *check.Variant, level string, side check.Player, seed int64) (*Match, error) {
+	return NewMatchFrom(v.NewGame(), level, side, seed)
+}
+
+// NewMatchFrom is NewMatch for a game already set up, such as one started
+// with a ballot.
+func NewMatchFrom(g *check.Game, level string, side check.Player, seed int64) (*Match, error) {
+	b, err := Lookup(level)
+	if err != nil {
+		return nil, err
+	}
+	return &Match{
+		Game: g,
+		Bot:  b,
+		Side: side,
+		rand: rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Reply plays the bot's move and returns it.
+func (m *Match) Reply() (*models.Move, error) {
+	if m.Game.Over() || m.Game.Turn() != m.Side {
+		return nil, ErrNotBotsTurn
+	}
+	return m.Bot.Play(m.Game, m.rand)
+}
+
+// Record returns the game record, naming the bot and its rating in the tags
+// of its side and the opponent in the other.
+func (m *Match) Record(opponent string, tags ...pdn.Tag) *pdn.Game {
+	g := pdn.Record(m.Game, tags...)
+	side, other := tagName(m.Side), tagName(!m.Side)
+	g.SetTag(side, m.Bot.Name)
+	g.SetTag(side+"Type", "program")
+	g.SetTag(side+"Elo", strconv.Itoa(m.Bot.Rating))
+	if opponent != "" {
+		g.SetTag(other, opponent)
+	}
+	return g
+}
+
+// Recorded returns the bot that played in g and its side, as written by
+// Match.Record.
+func Recorded(g *pdn.Game) (*Bot, check.Player, error) {
+	for _, side := range []check.Player{check.Black, check.White} {
+		name := tagName(side)
+		if g.Tag(name+"Type") != "program" {
+			continue
+		}
+		b, err := Lookup(g.Tag(name))
+		if err != nil {
+			return nil, side, err
+		}
+		return b, side, nil
+	}
+	return nil, check.Black, ErrUnknownBot
+}
+
+func tagName(p check.Player) string {
+	if p == check.Black {
+		return "Black"
+	}
+	return "White"
+}
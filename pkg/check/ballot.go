@@ -0,0 +1,122 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// ErrBallot is returned for a ballot that does not exist or cannot be played.
+var ErrBallot = errors.New("check: invalid ballot")
+
+// Ballot is an opening dealt by lot to start a game with, as tournaments do
+// so that games do not all follow the same well known lines. Both games of a
+// pair are played from the same ballot with the players swapping colours.
+type Ballot struct {
+	// Number identifies the ballot in its list, starting at 1.
+	Number int
+	Name   string
+	Moves  []*models.Move
+}
+
+func (b Ballot) String() string {
+	ls := make([]string, len(b.Moves))
+	for i, m := range b.Moves {
+		ls[i] = Notation(m)
+	}
+	return strings.Join(ls, " ")
+}
+
+// ReadBallots reads a list of ballots for v, one per line, numbered in the
+// order they come in. Tournaments publish the list of approved openings,
+// which leaves out those that lose by force. A line has the moves of the
+// ballot from the start, optionally followed by its name, such as
+// "11-15 23-19 8-11 Old Fourteenth". Blank lines and lines starting with #
+// are ignored.
+func ReadBallots(v *Variant, r io.Reader) ([]Ballot, error) {
+	var ballots []Ballot
+	err := readMoveLines(v, r, ErrBallot, func(n int, g *Game, moves int, name string) error {
+		if moves == 0 || len(g.pending) > 0 {
+			return fmt.Errorf("%w: line %d: expected complete moves", ErrBallot, n)
+		}
+		ballots = append(ballots, Ballot{
+			Number: len(ballots) + 1,
+			Name:   name,
+			Moves:  g.History(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ballots, nil
+}
+
+// Deal returns a ballot of the list chosen at random.
+func Deal(ballots []Ballot, r *rand.Rand) Ballot {
+	return ballots[r.Intn(len(ballots))]
+}
+
+// FindBallot returns the ballot of the list with the given number.
+func FindBallot(ballots []Ballot, number int) (Ballot, error) {
+	if number < 1 || number > len(ballots) {
+		return Ballot{}, fmt.Errorf("%w: no ballot %d among %d", ErrBallot, number, len(ballots))
+	}
+	return ballots[number-1], nil
+}
+
+// NewBallotGame returns a game of v started with the moves of b. The moves
+// are part of the game's history but cannot be taken back.
+func (v *Variant) NewBallotGame(b Ballot) (*Game, error) {
+	g := v.NewGame()
+	for _, m := range b.Moves {
+		if err := g.Apply(m); err != nil {
+			return nil, fmt.Errorf("%w %d: %v", ErrBallot, b.Number, err)
+		}
+	}
+	return g, g.FixBallot(b, 0)
+}
+
+// FixBallot records that g is a game started with b, as when a saved ballot
+// game is played again, so that its moves cannot be taken back. played is the
+// number of moves of the game before the history of g, which is not zero when
+// g was restored from a later position. The moves of b after them must come
+// first in the history.
+func (g *Game) FixBallot(b Ballot, played int) error {
+	fixed := len(b.Moves) - played
+	if fixed < 0 {
+		fixed = 0
+	}
+	if len(g.history) < fixed {
+		return fmt.Errorf("%w %d: the game has fewer moves", ErrBallot, b.Number)
+	}
+	for i := 0; i < fixed; i++ {
+		if Notation(g.history[i].move) != Notation(b.Moves[played+i]) {
+			return fmt.Errorf("%w %d: the game does not start with %s", ErrBallot, b.Number, b)
+		}
+	}
+	g.ballot, g.fixed = &b, fixed
+	return nil
+}
+
+// Model returns b as kept with a saved game.
+func (b Ballot) Model() *models.Ballot {
+	return &models.Ballot{Number: int32(b.Number), Name: b.Name, Moves: b.Moves}
+}
+
+// BallotFrom returns the ballot kept as m.
+func BallotFrom(m *models.Ballot) Ballot {
+	return Ballot{Number: int(m.Number), Name: m.Name, Moves: m.Moves}
+}
+
+// Ballot returns the ballot g was started with.
+func (g *Game) Ballot() (Ballot, bool) {
+	if g.ballot == nil {
+		return Ballot{}, false
+	}
+	return *g.ballot, true
+}
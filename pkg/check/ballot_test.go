@@ -0,0 +1,107 @@
+package check
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestBallots(t *testing.T) {
+	ballots, err := ReadBallots(English, strings.NewReader("9-13 21-17 5-9\n9-13 22-18 10-14\n11-15 23-19 8-11\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := FindBallot(ballots, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Number != 1 || b.String() != "9-13 21-17 5-9" {
+		t.Errorf("expected the first ballot got %d %q", b.Number, b)
+	}
+	for _, n := range []int{0, 4} {
+		if _, err := FindBallot(ballots, n); !errors.Is(err, ErrBallot) {
+			t.Errorf("%d: expected an invalid ballot got %v", n, err)
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	if b := Deal(ballots, r); b.Number < 1 || b.Number > len(ballots) {
+		t.Errorf("expected a ballot of the list got %d", b.Number)
+	}
+}
+
+func TestBallotGame(t *testing.T) {
+	ballots, err := ReadBallots(English, strings.NewReader("# approved\n11-15 23-19 8-11 Old Fourteenth\n\n9-14 22-17 11-15\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ballots) != 2 || ballots[0].Name != "Old Fourteenth" || ballots[1].Number != 2 {
+		t.Fatalf("unexpected ballots %v", ballots)
+	}
+	g, err := English.NewBallotGame(ballots[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := g.Ballot(); !ok || b.Number != 1 {
+		t.Errorf("expected the game to have ballot 1 got %v", b)
+	}
+	if len(g.History()) != 3 || g.Turn() != White {
+		t.Errorf("expected white to move after the ballot got %d moves", len(g.History()))
+	}
+	if g.CanUndo() {
+		t.Error("expected the ballot moves to stay")
+	}
+	if err := g.Apply(move(t, "22-17")); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Undo() || g.Undo() {
+		t.Error("expected only the move after the ballot to be taken back")
+	}
+	// A name without moves, and moves to occupied squares.
+	for _, s := range []string{"Old Fourteenth", "11-15 23-19 8-12", "11-16 24-19 16x23"} {
+		if _, err := ReadBallots(English, strings.NewReader(s)); !errors.Is(err, ErrBallot) {
+			t.Errorf("%q: expected an invalid ballot got %v", s, err)
+		}
+	}
+}
+
+func TestFixBallot(t *testing.T) {
+	ballots, err := ReadBallots(English, strings.NewReader("11-15 23-19 8-11 Old Fourteenth\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := ballots[0]
+	g := English.NewGame()
+	for _, s := range []string{"11-15", "23-19", "8-11", "22-17"} {
+		if err := g.Apply(move(t, s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.FixBallot(b, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Undo() || g.Undo() {
+		t.Error("expected only the move after the ballot to be taken back")
+	}
+	other := English.NewGame()
+	if err := other.Apply(move(t, "9-13")); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.FixBallot(b, 0); !errors.Is(err, ErrBallot) {
+		t.Errorf("expected a game of another opening to be refused got %v", err)
+	}
+	// A game restored from the position after the first two moves.
+	mid := English.NewGame()
+	for _, s := range []string{"11-15", "23-19"} {
+		if err := mid.Apply(move(t, s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resumed := NewGameFrom(mid.Board().Clone())
+	if err := resumed.Apply(move(t, "8-11")); err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.FixBallot(b, 2); err != nil || resumed.CanUndo() {
+		t.Errorf("expected the last ballot move to stay got %v", err)
+	}
+}
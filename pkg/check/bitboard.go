@@ -0,0 +1,234 @@
+package check
+
+import (
+	"math/bits"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// Masks of the 32 squares of an English draughts board, bit i is square i+1.
+const (
+	// evenRows are the rows starting with a light square, rows 1, 3, 5 and 7
+	// counting from the top.
+	evenRows uint32 = 0x0F0F0F0F
+	oddRows  uint32 = 0xF0F0F0F0
+	// leftEdge and rightEdge are the first and last squares of each row.
+	leftEdge  uint32 = 0x11111111
+	rightEdge uint32 = 0x88888888
+	topRow    uint32 = 0x0000000F
+	bottomRow uint32 = 0xF0000000
+)
+
+// Bitboard is an English draughts position packed into bit masks, bit i is
+// set when square i+1 holds a piece. It is much cheaper to generate and play
+// moves on than Board, see Board.Bitboard.
+type Bitboard struct {
+	Black uint32
+	White uint32
+	Kings uint32
+	Turn  Player
+}
+
+// BitMove is a move on a Bitboard. From and To have a single bit set,
+// Captures has the squares of the captured pieces.
+type BitMove struct {
+	From     uint32
+	To       uint32
+	Captures uint32
+	// Route has the direction of each jump of a capture in two bits, the
+	// first jump in the lowest. A king can take the same pieces going round
+	// either way, the routes are different moves.
+	Route uint32
+}
+
+// Bitboard returns b as a Bitboard. It reports false when b is not English
+// draughts or a piece is in the middle of a multiple jump.
+func (b *Board) Bitboard() (Bitboard, bool) {
+	if b.variant() != English || b.PieceRequiringFurtherCaptureMoves != nil {
+		return Bitboard{}, false
+	}
+	bb := Bitboard{Turn: Player(b.PlayertTurn)}
+	for _, p := range b.UncapturedPieces {
+		bit := uint32(1) << (p.Position - 1)
+		if Player(p.Player) == Black {
+			bb.Black |= bit
+		} else {
+			bb.White |= bit
+		}
+		if p.King {
+			bb.Kings |= bit
+		}
+	}
+	return bb, true
+}
+
+// Board returns bb as a Board.
+func (bb Bitboard) Board() *Board {
+	b := &Board{PlayertTurn: bool(bb.Turn)}
+	for _, p := range []Player{Black, White} {
+		for set := bb.pieces(p); set != 0; set &= set - 1 {
+			bit := set & -set
+			b.Pieces = append(b.Pieces, &models.Piece{
+				Id:       int32(len(b.Pieces) + 1),
+				Player:   bool(p),
+				Position: square(bit),
+				King:     bb.Kings&bit != 0,
+			})
+		}
+	}
+	b.sort_pieces()
+	b.resetPieces()
+	return b
+}
+
+func (bb Bitboard) pieces(p Player) uint32 {
+	if p == Black {
+		return bb.Black
+	}
+	return bb.White
+}
+
+func square(bit uint32) int32 {
+	return int32(bits.TrailingZeros32(bit) + 1)
+}
+
+// Directions in the order the generic generator uses them.
+const (
+	upLeft = iota
+	upRight
+	downLeft
+	downRight
+)
+
+// step moves every bit of x one square in direction d, dropping those that
+// would leave the board.
+func step(x uint32, d int) uint32 {
+	switch d {
+	case upLeft:
+		return (x&evenRows)>>4 | (x&oddRows&^leftEdge)>>5
+	case upRight:
+		return (x&evenRows&^rightEdge)>>3 | (x&oddRows)>>4
+	case downLeft:
+		return (x&evenRows)<<4 | (x&oddRows&^leftEdge)<<3
+	default:
+		return (x&evenRows&^rightEdge)<<5 | (x&oddRows)<<4
+	}
+}
+
+var (
+	kingDirs  = []int{upLeft, upRight, downLeft, downRight}
+	blackDirs = kingDirs[2:]
+	whiteDirs = kingDirs[:2]
+)
+
+// dirs returns the directions a piece of p moves in.
+func dirs(p Player, king bool) []int {
+	switch {
+	case king:
+		return kingDirs
+	case p == Black:
+		return blackDirs
+	default:
+		return whiteDirs
+	}
+}
+
+func crownRow(p Player) uint32 {
+	if p == Black {
+		return bottomRow
+	}
+	return topRow
+}
+
+// Moves appends the legal moves of the player to move to moves. Only
+// captures are returned when there is one.
+func (bb Bitboard) Moves(moves []BitMove) []BitMove {
+	own, enemy := bb.White, bb.Black
+	if bb.Turn == Black {
+		own, enemy = enemy, own
+	}
+	empty := ^(own | enemy)
+	start := len(moves)
+	for set := own; set != 0; set &= set - 1 {
+		from := set & -set
+		king := bb.Kings&from != 0
+		moves = bb.jumps(from, from, king, enemy, empty|from, 0, 0, moves)
+	}
+	if len(moves) > start {
+		return moves
+	}
+	for set := own; set != 0; set &= set - 1 {
+		from := set & -set
+		for _, d := range dirs(bb.Turn, bb.Kings&from != 0) {
+			if to := step(from, d) & empty; to != 0 {
+				moves = append(moves, BitMove{From: from, To: to})
+			}
+		}
+	}
+	return moves
+}
+
+// jumps appends every capture that goes on from at, for the piece that
+// started on from and has captured caps so far along route. Captured pieces
+// stay on the board until the move ends. empty includes from, which the piece
+// has left.
+func (bb Bitboard) jumps(from, at uint32, king bool, enemy, empty, caps, route uint32, moves []BitMove) []BitMove {
+	shift := 2 * bits.OnesCount32(caps)
+	for _, d := range dirs(bb.Turn, king) {
+		over := step(at, d) & enemy &^ caps
+		if over == 0 {
+			continue
+		}
+		to := step(over, d) & empty
+		if to == 0 {
+			continue
+		}
+		r := route | uint32(d)<<shift
+		if !king && to&crownRow(bb.Turn) != 0 {
+			moves = append(moves, BitMove{From: from, To: to, Captures: caps | over, Route: r})
+			continue
+		}
+		n := len(moves)
+		moves = bb.jumps(from, to, king, enemy, empty, caps|over, r, moves)
+		if len(moves) == n {
+			moves = append(moves, BitMove{From: from, To: to, Captures: caps | over, Route: r})
+		}
+	}
+	return moves
+}
+
+// Play returns the position after m, which must be legal.
+func (bb Bitboard) Play(m BitMove) Bitboard {
+	own, enemy := &bb.White, &bb.Black
+	if bb.Turn == Black {
+		own, enemy = enemy, own
+	}
+	*own = *own&^m.From | m.To
+	*enemy &^= m.Captures
+	king := bb.Kings&m.From != 0
+	bb.Kings &^= m.From | m.Captures
+	if king || m.To&crownRow(bb.Turn) != 0 {
+		bb.Kings |= m.To
+	}
+	bb.Turn = !bb.Turn
+	return bb
+}
+
+// Move returns m as a models.Move, with the squares it passes through.
+func (bb Bitboard) Move(m BitMove) *models.Move {
+	from := square(m.From)
+	if m.Captures == 0 {
+		return newMove([]int32{from, square(m.To)}, nil)
+	}
+	path := []int32{from}
+	var caps []int32
+	at := m.From
+	for i := 0; i < bits.OnesCount32(m.Captures); i++ {
+		d := int(m.Route >> (2 * i) & 3)
+		over := step(at, d)
+		at = step(over, d)
+		path = append(path, square(at))
+		caps = append(caps, square(over))
+	}
+	return newMove(path, caps)
+}
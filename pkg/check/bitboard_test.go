@@ -0,0 +1,95 @@
+package check
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+func TestBitboardPerft(t *testing.T) {
+	bb, ok := English.NewBoard().Bitboard()
+	if !ok {
+		t.Fatal("expected a bitboard for english draughts")
+	}
+	expect := []uint64{7, 49, 302, 1469, 7361, 36768, 179740}
+	for i, n := range expect {
+		if got := bb.Perft(i + 1); got != n {
+			t.Errorf("depth %d: expected %d got %d", i+1, n, got)
+		}
+	}
+}
+
+// TestBitboardMoves plays random games and checks that the bitboard finds the
+// same moves as the generic generator all along.
+func TestBitboardMoves(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for game := 0; game < 50; game++ {
+		b := English.NewBoard()
+		for ply := 0; ply < 200; ply++ {
+			bb, _ := b.Bitboard()
+			if !samePosition(bb.Board(), b) {
+				t.Fatalf("bitboard does not convert back to %s", b.FEN())
+			}
+			list := bb.Moves(nil)
+			expect := b.position().moves()
+			got := make([][]int32, len(list))
+			for i, m := range list {
+				got[i] = bb.Move(m).Path
+			}
+			if !reflect.DeepEqual(got, paths(expect)) {
+				t.Fatalf("%s: expected %v got %v", b.FEN(), paths(expect), got)
+			}
+			if len(expect) == 0 {
+				break
+			}
+			i := r.Intn(len(expect))
+			b.perform(expect[i], true)
+			if next := bb.Play(list[i]); !samePosition(next.Board(), b) {
+				t.Fatalf("expected %s after %v got %s", b.FEN(), expect[i].Path, next.Board().FEN())
+			}
+		}
+	}
+}
+
+// TestBitboardRoutes checks a king that takes the same pieces going round
+// either way, each route is a move of its own.
+func TestBitboardRoutes(t *testing.T) {
+	b, err := ParseFEN("W:WK9:B6,7,14,15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb, _ := b.Bitboard()
+	var got [][]int32
+	for _, m := range bb.Moves(nil) {
+		got = append(got, bb.Move(m).Path)
+	}
+	expect := paths(b.position().moves())
+	if len(expect) != 2 || !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expected %v got %v", expect, got)
+	}
+	if n := bb.Perft(1); n != 2 {
+		t.Errorf("expected perft 2 got %d", n)
+	}
+	for _, path := range expect {
+		g := NewGameFrom(b.Clone())
+		if err := g.Apply(&models.Move{Path: path}); err != nil {
+			t.Errorf("%v: %v", path, err)
+		}
+	}
+}
+
+func BenchmarkPerftBitboard(b *testing.B) {
+	bb, _ := English.NewBoard().Bitboard()
+	for i := 0; i < b.N; i++ {
+		bb.Perft(6)
+	}
+}
+
+func BenchmarkPerftBoard(b *testing.B) {
+	board := English.NewBoard()
+	for i := 0; i < b.N; i++ {
+		board.perft(4)
+	}
+}
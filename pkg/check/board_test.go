@@ -1,9 +1,256 @@
 package check
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/gernest/8x8/pkg/models"
+	"github.com/golang/protobuf/proto"
 )
 
 func TestBoard(t *testing.T) {
+	g := NewGame()
+	if g.Turn() != Black {
+		t.Fatalf("expected black to move first got %v", g.Turn())
+	}
+	if n := len(g.LegalMoves()); n != 7 {
+		t.Fatalf("expected 7 opening moves got %d", n)
+	}
+	err := g.Apply(&models.Move{From: 21, To: 17})
+	if !errors.Is(err, ErrIllegalMove) {
+		t.Fatalf("expected illegal move got %v", err)
+	}
+	moves := []*models.Move{
+		{From: 11, To: 15},
+		{From: 22, To: 18},
+		{From: 15, To: 22},
+	}
+	for _, m := range moves {
+		if err := g.Apply(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.Board().get_piece_by_position(18) != nil {
+		t.Error("expected piece on 18 to be captured")
+	}
+	if g.Turn() != White {
+		t.Errorf("expected white to move got %v", g.Turn())
+	}
+	if g.Result() != Ongoing {
+		t.Errorf("expected ongoing game got %v", g.Result())
+	}
+}
+
+func TestUndo(t *testing.T) {
+	g := NewGame()
+	start := g.Board().Clone()
+	moves := []*models.Move{
+		{From: 11, To: 15},
+		{From: 22, To: 18},
+		{From: 15, To: 22},
+	}
+	for _, m := range moves {
+		if err := g.Apply(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	after := g.Board().Clone()
+	for g.Undo() {
+	}
+	if !samePosition(g.Board(), start) {
+		t.Fatal("expected undo to restore the starting position")
+	}
+	if g.Turn() != Black {
+		t.Errorf("expected black to move got %v", g.Turn())
+	}
+	for g.Redo() {
+	}
+	if !samePosition(g.Board(), after) {
+		t.Fatal("expected redo to replay all moves")
+	}
+	if len(g.History()) != len(moves) {
+		t.Errorf("expected %d moves in history got %d", len(moves), len(g.History()))
+	}
+}
+
+func TestClone(t *testing.T) {
+	g := NewGame()
+	c := g.Board().Clone()
+	if err := g.Apply(&models.Move{From: 9, To: 13}); err != nil {
+		t.Fatal(err)
+	}
+	if c.get_piece_by_position(9) == nil || c.get_piece_by_position(13) != nil {
+		t.Error("expected clone to be unaffected by moves on the original")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	b, err := ParseFEN("B:W14,15,23,K30:B10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := proto.Marshal(b.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &models.Board{}
+	if err := proto.Unmarshal(data, m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.UncapturedPieces) != 0 || len(m.PositionPieces) != 0 {
+		t.Error("expected no derived fields in the snapshot")
+	}
+	r := Restore(m)
+	if !samePosition(b, r) || r.Hash() != b.Hash() || len(r.Moves()) != len(b.Moves()) {
+		t.Errorf("expected %s got %s", b.FEN(), r.FEN())
+	}
+	r.Play(r.Moves()[0])
+	if r.get_piece_by_position(10) != nil {
+		t.Error("expected moves to be played on the restored board")
+	}
+}
+
+func samePosition(a, b *Board) bool {
+	if a.PlayertTurn != b.PlayertTurn || len(a.UncapturedPieces) != len(b.UncapturedPieces) {
+		return false
+	}
+	for pos, p := range a.PositionPieces {
+		o := b.PositionPieces[pos]
+		if o == nil || o.Player != p.Player || o.King != p.King {
+			return false
+		}
+	}
+	return true
+}
+
+func TestResult(t *testing.T) {
+	g := NewGame()
+	if err := g.Resign(White); err != nil {
+		t.Fatal(err)
+	}
+	if g.Result() != BlackWins || g.Reason() != Resignation {
+		t.Errorf("expected black to win by resignation got %v %v", g.Result(), g.Reason())
+	}
+	if err := g.AgreeDraw(); !errors.Is(err, ErrResultSet) {
+		t.Errorf("expected %v got %v", ErrResultSet, err)
+	}
+	if err := g.Apply(&models.Move{From: 9, To: 13}); !errors.Is(err, ErrGameOver) {
+		t.Errorf("expected %v got %v", ErrGameOver, err)
+	}
+}
+
+// kingsGame returns a game with a black king on 1 and a white king on 32.
+func kingsGame() *Game {
+	return testGame(English, Black, []int32{-1}, []int32{-32})
+}
+
+func TestDrawRules(t *testing.T) {
+	shuffle := []*models.Move{
+		{From: 1, To: 6},
+		{From: 32, To: 27},
+		{From: 6, To: 1},
+		{From: 27, To: 32},
+	}
+	t.Run("repetition", func(t *testing.T) {
+		g := kingsGame()
+		for i := 0; i < 2; i++ {
+			for _, m := range shuffle {
+				if err := g.Apply(m); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if g.Result() != Draw || g.Reason() != DrawRule {
+			t.Errorf("expected draw by repetition got %v %v", g.Result(), g.Reason())
+		}
+	})
+	t.Run("move limit", func(t *testing.T) {
+		g := kingsGame()
+		g.SetDrawRules(DrawRules{MoveLimit: 2})
+		for _, m := range shuffle {
+			if err := g.Apply(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if g.Result() != Draw || g.Reason() != DrawRule {
+			t.Errorf("expected draw by move limit got %v %v", g.Result(), g.Reason())
+		}
+	})
+	t.Run("agreement", func(t *testing.T) {
+		g := kingsGame()
+		if err := g.OfferDraw(Black); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.AcceptDraw(Black); !errors.Is(err, ErrNoDrawOffer) {
+			t.Errorf("expected %v got %v", ErrNoDrawOffer, err)
+		}
+		if err := g.AcceptDraw(White); err != nil {
+			t.Fatal(err)
+		}
+		if g.Result() != Draw || g.Reason() != Agreement {
+			t.Errorf("expected draw by agreement got %v %v", g.Result(), g.Reason())
+		}
+	})
+}
+
+func TestMultipleJump(t *testing.T) {
+	// A black man on 9 can jump 14 and 23 landing on 27.
+	setup := func() *Game {
+		return testGame(English, Black, []int32{9}, []int32{14, 23, 32})
+	}
+	g := setup()
+	moves := g.LegalMoves()
+	if len(moves) != 1 {
+		t.Fatalf("expected one capture sequence got %d", len(moves))
+	}
+	m := moves[0]
+	if !equal(m.Path, []int32{9, 18, 27}) || !equal(m.Captures, []int32{14, 23}) {
+		t.Fatalf("unexpected sequence %v", m)
+	}
+
+	if err := g.Apply(&models.Move{From: 9, To: 27}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Turn() != White || len(g.Board().WhitePieces) != 1 {
+		t.Errorf("expected both white men captured and white to move")
+	}
+	if !g.Undo() || len(g.Board().WhitePieces) != 3 {
+		t.Fatal("expected undo to restore both captured men")
+	}
+
+	g = setup()
+	if err := g.Apply(&models.Move{From: 9, To: 18}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Turn() != Black {
+		t.Fatal("expected black to continue capturing")
+	}
+	if err := g.Apply(&models.Move{From: 18, To: 27}); err != nil {
+		t.Fatal(err)
+	}
+	h := g.History()
+	if len(h) != 1 || !equal(h[0].Path, m.Path) {
+		t.Errorf("expected jumps to be recorded as one move got %v", h)
+	}
+
+	g = setup()
+	if err := g.Apply(&models.Move{Path: []int32{9}}); err == nil {
+		t.Error("expected a single square to be rejected as a jump")
+	}
+}
 
+func TestPartialSteps(t *testing.T) {
+	g := English.NewGame()
+	if err := g.Apply(&models.Move{Path: []int32{11}}); err == nil {
+		t.Error("expected a single square to be rejected")
+	}
+	if g.Capturing() || len(g.History()) != 0 {
+		t.Fatal("expected the rejected move to leave the game alone")
+	}
+	if err := g.Apply(&models.Move{Path: []int32{11, 15}}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Turn() != White || g.Capturing() {
+		t.Error("expected 11-15 to be played whole")
+	}
 }
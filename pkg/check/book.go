@@ -0,0 +1,260 @@
+package check
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// ErrBook is returned when reading a book or a names file that is not valid.
+var ErrBook = errors.New("check: invalid book")
+
+// BookMove is a move a Book suggests.
+type BookMove struct {
+	Move *models.Move
+	// Weight is how often the move is chosen relative to the other moves of
+	// the position.
+	Weight int
+}
+
+// Book is an opening book: the moves worth playing in positions of the first
+// moves of a game, found by their hash, and the names of well known openings.
+type Book struct {
+	Variant *Variant
+	moves   map[uint64][]bookMove
+	names   map[uint64]string
+}
+
+// bookMove is a move as the book keeps it, without the position it is played
+// in.
+type bookMove struct {
+	path    []int32
+	capture bool
+	weight  int
+}
+
+// NewBook returns an empty book for v.
+func NewBook(v *Variant) *Book {
+	return &Book{
+		Variant: v,
+		moves:   make(map[uint64][]bookMove),
+		names:   make(map[uint64]string),
+	}
+}
+
+// Len returns the number of positions with moves in the book.
+func (bk *Book) Len() int {
+	return len(bk.moves)
+}
+
+// Add adds weight to move m in position b, adding the move when the book does
+// not have it yet.
+func (bk *Book) Add(b *Board, m *models.Move, weight int) {
+	h := b.Hash()
+	moves := bk.moves[h]
+	for i := range moves {
+		if equal(moves[i].path, m.Path) {
+			moves[i].weight += weight
+			return
+		}
+	}
+	bk.moves[h] = append(moves, bookMove{path: m.Path, capture: len(m.Captures) > 0, weight: weight})
+}
+
+// Moves returns the moves of the book in position b, the heaviest first.
+// Moves that are not legal on b, which can only come from another position
+// with the same hash, are left out.
+func (bk *Book) Moves(b *Board) []BookMove {
+	var moves []BookMove
+	legal := b.Moves()
+	for _, bm := range bk.moves[b.Hash()] {
+		for _, m := range legal {
+			if equal(m.Path, bm.path) {
+				moves = append(moves, BookMove{Move: m, Weight: bm.weight})
+				break
+			}
+		}
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].Weight > moves[j].Weight
+	})
+	return moves
+}
+
+// Pick returns a move of the book in position b, each with a chance
+// proportional to its weight. It reports false when the book has nothing to
+// suggest.
+func (bk *Book) Pick(b *Board, r *rand.Rand) (*models.Move, bool) {
+	moves := bk.Moves(b)
+	total := 0
+	for _, m := range moves {
+		total += m.Weight
+	}
+	if total <= 0 {
+		return nil, false
+	}
+	n := r.Intn(total)
+	for _, m := range moves {
+		if n < m.Weight {
+			return m.Move, true
+		}
+		n -= m.Weight
+	}
+	return nil, false
+}
+
+// Name names the position on b.
+func (bk *Book) Name(b *Board, name string) {
+	bk.names[b.Hash()] = name
+}
+
+// Opening returns the name of the opening g is in: the name of the last
+// named position the game went through.
+func (bk *Book) Opening(g *Game) string {
+	for i := len(g.history) - 1; i >= 0; i-- {
+		if name, ok := bk.names[g.history[i].hash]; ok {
+			return name
+		}
+	}
+	return bk.names[g.start]
+}
+
+// ReadNames reads opening names. Each line has the moves of an opening from
+// the start followed by its name, such as "11-15 23-19 8-11 Old Fourteenth".
+// Blank lines and lines starting with # are ignored.
+func (bk *Book) ReadNames(r io.Reader) error {
+	return readMoveLines(bk.Variant, r, ErrBook, func(n int, g *Game, moves int, name string) error {
+		if moves == 0 || name == "" {
+			return fmt.Errorf("%w: line %d: expected moves and a name", ErrBook, n)
+		}
+		bk.Name(g.board, name)
+		return nil
+	})
+}
+
+// readMoveLines reads lines of moves from the start of a game of v, followed
+// by some text, skipping blank lines and lines starting with #. It calls fn
+// with the number of each line, the game after its moves, how many there
+// were and the text. A move that cannot be played is an error wrapping
+// invalid.
+func readMoveLines(v *Variant, r io.Reader, invalid error, fn func(n int, g *Game, moves int, text string) error) error {
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		g := v.NewGame()
+		i := 0
+		for ; i < len(fields); i++ {
+			m, err := ParseMove(fields[i])
+			if err != nil {
+				break
+			}
+			if err := g.Apply(m); err != nil {
+				return fmt.Errorf("%w: line %d: %v", invalid, n, err)
+			}
+		}
+		if err := fn(n, g, i, strings.Join(fields[i:], " ")); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// bookHeader starts a book file, followed by the name of its variant.
+const bookHeader = "8x8 book"
+
+// WriteTo writes the book to w as text: a header line naming the variant,
+// then a line per move of a position, "move <hash> <move> <weight>", and
+// one per name, "name <hash> <name>". Hashes are in hexadecimal.
+func (bk *Book) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	fmt.Fprintf(bw, "%s %s\n", bookHeader, bk.Variant.Name)
+	keys := make([]uint64, 0, len(bk.moves))
+	for h := range bk.moves {
+		keys = append(keys, h)
+	}
+	sortKeys(keys)
+	for _, h := range keys {
+		for _, m := range bk.moves[h] {
+			move := &models.Move{Path: m.path}
+			if m.capture {
+				// Notation only needs to know there are captures.
+				move.Captures = m.path[1:]
+			}
+			fmt.Fprintf(bw, "move %016x %s %d\n", h, Notation(move), m.weight)
+		}
+	}
+	keys = keys[:0]
+	for h := range bk.names {
+		keys = append(keys, h)
+	}
+	sortKeys(keys)
+	for _, h := range keys {
+		fmt.Fprintf(bw, "name %016x %s\n", h, bk.names[h])
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+func sortKeys(keys []uint64) {
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+}
+
+// ReadBook reads a book written by WriteTo.
+func ReadBook(r io.Reader) (*Book, error) {
+	s := bufio.NewScanner(r)
+	if !s.Scan() || !strings.HasPrefix(s.Text(), bookHeader+" ") {
+		return nil, fmt.Errorf("%w: missing header", ErrBook)
+	}
+	v := LookupVariant(strings.TrimPrefix(s.Text(), bookHeader+" "))
+	if v == nil {
+		return nil, fmt.Errorf("%w: unknown variant in %q", ErrBook, s.Text())
+	}
+	bk := NewBook(v)
+	for n := 2; s.Scan(); n++ {
+		fields := strings.SplitN(s.Text(), " ", 3)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%w: line %d", ErrBook, n)
+		}
+		h, err := strconv.ParseUint(fields[1], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrBook, n, err)
+		}
+		switch fields[0] {
+		case "move":
+			rest := strings.Fields(fields[2])
+			if len(rest) != 2 {
+				return nil, fmt.Errorf("%w: line %d: expected a move and a weight", ErrBook, n)
+			}
+			m, err := ParseMove(rest[0])
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrBook, n, err)
+			}
+			weight, err := strconv.Atoi(rest[1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrBook, n, err)
+			}
+			bk.moves[h] = append(bk.moves[h], bookMove{
+				path:    m.Path,
+				capture: strings.Contains(rest[0], "x"),
+				weight:  weight,
+			})
+		case "name":
+			bk.names[h] = fields[2]
+		default:
+			return nil, fmt.Errorf("%w: line %d: unknown entry %q", ErrBook, n, fields[0])
+		}
+	}
+	return bk, s.Err()
+}
@@ -0,0 +1,125 @@
+package check
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+func move(t *testing.T, s string) *models.Move {
+	t.Helper()
+	m, err := ParseMove(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestBook(t *testing.T) {
+	bk := NewBook(English)
+	start := English.NewBoard()
+	bk.Add(start, move(t, "11-15"), 3)
+	bk.Add(start, move(t, "9-14"), 1)
+	bk.Add(start, move(t, "11-15"), 2)
+	bk.Add(start, move(t, "12-16"), 0)
+	if bk.Len() != 1 {
+		t.Errorf("expected one position got %d", bk.Len())
+	}
+	var got []string
+	for _, m := range bk.Moves(start) {
+		got = append(got, Notation(m.Move))
+	}
+	if want := []string{"11-15", "9-14", "12-16"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+	r := rand.New(rand.NewSource(1))
+	count := make(map[string]int)
+	for i := 0; i < 600; i++ {
+		m, ok := bk.Pick(start, r)
+		if !ok {
+			t.Fatal("expected a book move")
+		}
+		count[Notation(m)]++
+	}
+	if count["12-16"] != 0 || count["11-15"] < 400 || count["9-14"] < 50 {
+		t.Errorf("expected moves to be picked by weight got %v", count)
+	}
+	g := NewGame()
+	g.Apply(move(t, "11-15"))
+	if _, ok := bk.Pick(g.Board(), r); ok {
+		t.Error("expected nothing for a position out of the book")
+	}
+}
+
+func TestOpeningNames(t *testing.T) {
+	bk := NewBook(English)
+	err := bk.ReadNames(strings.NewReader("# test names\n11-15 Opening A\n11-15 23-19 8-11 Ballot B\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGame()
+	if name := bk.Opening(g); name != "" {
+		t.Errorf("expected no name at the start got %q", name)
+	}
+	for i, c := range []struct{ move, name string }{
+		{"11-15", "Opening A"},
+		{"23-19", "Opening A"},
+		{"8-11", "Ballot B"},
+		{"22-17", "Ballot B"},
+	} {
+		if err := g.Apply(move(t, c.move)); err != nil {
+			t.Fatal(err)
+		}
+		if name := bk.Opening(g); name != c.name {
+			t.Errorf("move %d: expected %q got %q", i+1, c.name, name)
+		}
+	}
+	for _, s := range []string{"11-15", "Only a name", "11-17 Illegal"} {
+		if err := bk.ReadNames(strings.NewReader(s)); !errors.Is(err, ErrBook) {
+			t.Errorf("%q: expected an invalid book got %v", s, err)
+		}
+	}
+}
+
+func TestBookFile(t *testing.T) {
+	bk := NewBook(English)
+	b, err := ParseFEN("B:W14,15,23:B10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bk.Add(b, b.Moves()[1], 2)
+	bk.Add(English.NewBoard(), move(t, "11-15"), 1)
+	bk.Name(b, "Double jump")
+	var buf bytes.Buffer
+	if _, err := bk.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), " 10x19x26 2\n") {
+		t.Errorf("expected the capture to be written got\n%s", buf.String())
+	}
+	read, err := ReadBook(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Variant != English || read.Len() != 2 {
+		t.Errorf("expected two english positions got %d %s", read.Len(), read.Variant.Name)
+	}
+	if got := read.Moves(b); len(got) != 1 || len(got[0].Move.Captures) != 2 {
+		t.Errorf("expected the capture to be read back got %v", got)
+	}
+	var again bytes.Buffer
+	read.WriteTo(&again)
+	if again.String() != buf.String() {
+		t.Errorf("expected the same book got\n%s", again.String())
+	}
+	for _, s := range []string{"", "8x8 book chess\n", "8x8 book english\nmove 12 11-15\n", "8x8 book english\nline 1 2\n"} {
+		if _, err := ReadBook(strings.NewReader(s)); !errors.Is(err, ErrBook) {
+			t.Errorf("%q: expected an invalid book got %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package check
+
+import "errors"
+
+// ErrNoDrawOffer is returned when accepting a draw nobody offered.
+var ErrNoDrawOffer = errors.New("no draw offer to accept")
+
+// DrawRules decides when a game is drawn without the players agreeing to it.
+type DrawRules struct {
+	// MoveLimit draws the game after each player made this many moves in a
+	// row with kings only and without capturing. Zero disables the rule.
+	MoveLimit int
+	// Repetitions draws the game when the same position with the same player
+	// to move occurs this many times. Zero disables the rule.
+	Repetitions int
+}
+
+// DefaultDrawRules are the 40 move rule and threefold repetition of English
+// draughts.
+var DefaultDrawRules = DrawRules{
+	MoveLimit:   40,
+	Repetitions: 3,
+}
+
+// SetDrawRules changes the draw rules of the game. They are checked after the
+// next move.
+func (g *Game) SetDrawRules(r DrawRules) {
+	g.rules = r
+}
+
+// DrawRules returns the draw rules of the game.
+func (g *Game) DrawRules() DrawRules {
+	return g.rules
+}
+
+// QuietMoves returns the number of moves at the end of the game made by kings
+// without capturing. Only these moves can be undone, any other move makes
+// every position before it unreachable, so the draw rules apply the same to a
+// game restarted with NewGameFrom after such a move.
+func (g *Game) QuietMoves() int {
+	quiet := 0
+	for i := len(g.history) - 1; i >= 0; i-- {
+		u := g.history[i]
+		if !u.king || len(u.captured) != 0 {
+			break
+		}
+		quiet++
+	}
+	return quiet
+}
+
+// drawn reports whether the last move drew the game by g.rules.
+func (g *Game) drawn() bool {
+	if g.rules.MoveLimit == 0 && g.rules.Repetitions == 0 {
+		return false
+	}
+	quiet := g.QuietMoves()
+	if g.rules.MoveLimit > 0 && quiet >= 2*g.rules.MoveLimit {
+		return true
+	}
+	if g.rules.Repetitions > 0 {
+		current := g.history[len(g.history)-1].hash
+		seen := 1
+		from := len(g.history) - 1 - quiet
+		for i := len(g.history) - 2; i >= from; i-- {
+			h := g.start
+			if i >= 0 {
+				h = g.history[i].hash
+			}
+			if h == current {
+				seen++
+			}
+		}
+		if seen >= g.rules.Repetitions {
+			return true
+		}
+	}
+	return false
+}
+
+// OfferDraw records that p offers a draw. The offer stands until the opponent
+// accepts it or the next move is applied.
+func (g *Game) OfferDraw(p Player) error {
+	if g.Over() {
+		return ErrResultSet
+	}
+	g.offer, g.offered = p, true
+	return nil
+}
+
+// AcceptDraw ends the game in a draw when p accepts the opponent's standing
+// offer.
+func (g *Game) AcceptDraw(p Player) error {
+	if !g.offered || g.offer == p {
+		return ErrNoDrawOffer
+	}
+	return g.end(Draw, Agreement)
+}
@@ -0,0 +1,259 @@
+package check
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrWeights is returned for a weights file that cannot be read.
+var ErrWeights = errors.New("check: invalid weights")
+
+// Feature is a term of the position evaluation. Each is counted for both
+// players, the evaluation uses the difference.
+type Feature int
+
+const (
+	// Material is the number of pieces.
+	Material Feature = iota
+	// Kings is the number of kings, the value of a king over a man.
+	Kings
+	// BackRank is the number of men still guarding their own back row
+	// against the opponent's men being crowned.
+	BackRank
+	// Center is the number of pieces on the squares in the middle of the
+	// board.
+	Center
+	// Mobility is the number of legal moves.
+	Mobility
+	// Runaway is the number of men no opposing piece stands in front of, so
+	// nothing can stop them from being crowned.
+	Runaway
+	// Tempo is the number of rows men have advanced.
+	Tempo
+
+	// NumFeatures is the number of features.
+	NumFeatures
+)
+
+var featureNames = [NumFeatures]string{
+	Material: "material",
+	Kings:    "kings",
+	BackRank: "back_rank",
+	Center:   "center",
+	Mobility: "mobility",
+	Runaway:  "runaway",
+	Tempo:    "tempo",
+}
+
+func (f Feature) String() string {
+	if f < 0 || f >= NumFeatures {
+		return "feature(" + strconv.Itoa(int(f)) + ")"
+	}
+	return featureNames[f]
+}
+
+// Terms are the values of the features of a position, the player to move's
+// count minus the opponent's.
+type Terms [NumFeatures]int
+
+// Weights are what each feature is worth, in hundredths of a man.
+type Weights [NumFeatures]int
+
+// Evaluate returns the score of a position with the given terms for the
+// player to move.
+func (w *Weights) Evaluate(t Terms) int {
+	score := 0
+	for i, x := range t {
+		score += w[i] * x
+	}
+	return score
+}
+
+// DefaultWeights returns the weights the engine plays v with when given none.
+func (v *Variant) DefaultWeights() Weights {
+	w := Weights{
+		Material: 100,
+		Kings:    30,
+		BackRank: 6,
+		Center:   4,
+		Mobility: 2,
+		Runaway:  25,
+		Tempo:    1,
+	}
+	if v.FlyingKings {
+		w[Kings] = 200
+	}
+	return w
+}
+
+// ReadWeights reads a weights file on top of w. Each line gives a feature
+// name and its weight, such as "kings 30"; blank lines and lines starting
+// with # are ignored and features not mentioned keep their weight.
+func (w *Weights) ReadWeights(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("%w: line %d: expected a feature and a weight", ErrWeights, n)
+		}
+		f, ok := ParseFeature(fields[0])
+		if !ok {
+			return fmt.Errorf("%w: line %d: unknown feature %q", ErrWeights, n, fields[0])
+		}
+		x, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return fmt.Errorf("%w: line %d: %v", ErrWeights, n, err)
+		}
+		w[f] = x
+	}
+	return s.Err()
+}
+
+// WriteWeights writes w in the format ReadWeights reads.
+func (w *Weights) WriteWeights(out io.Writer) error {
+	bw := bufio.NewWriter(out)
+	fmt.Fprintln(bw, "# feature weight, in hundredths of a man")
+	for f, x := range w {
+		fmt.Fprintf(bw, "%s %d\n", Feature(f), x)
+	}
+	return bw.Flush()
+}
+
+// LoadWeights returns the default weights of v overridden by the weights
+// file called name.
+func (v *Variant) LoadWeights(name string) (Weights, error) {
+	w := v.DefaultWeights()
+	f, err := os.Open(name)
+	if err != nil {
+		return w, err
+	}
+	defer f.Close()
+	if err := w.ReadWeights(f); err != nil {
+		return w, fmt.Errorf("%s: %w", name, err)
+	}
+	return w, nil
+}
+
+// ParseFeature returns the feature called name, see Feature.String.
+func ParseFeature(name string) (Feature, bool) {
+	for f, n := range featureNames {
+		if n == name {
+			return Feature(f), true
+		}
+	}
+	return 0, false
+}
+
+// Features returns the terms of b for the player to move.
+func (b *Board) Features() Terms {
+	var occ occupancy
+	for _, p := range b.UncapturedPieces {
+		occ[p.Position] = pieceCode(Player(p.Player), p.King)
+	}
+	other := b.Clone()
+	other.switch_turn()
+	other.PieceRequiringFurtherCaptureMoves = nil
+	mobility := len(b.get_possible_moves()) - len(other.get_possible_moves())
+	return b.variant().terms(&occ, Player(b.PlayertTurn), mobility)
+}
+
+// Features returns the terms of bb for the player to move, the same as those
+// of the Board it stands for.
+func (bb Bitboard) Features() Terms {
+	var occ occupancy
+	for _, p := range []Player{Black, White} {
+		for x := bb.pieces(p); x != 0; x &= x - 1 {
+			bit := x & -x
+			occ[square(bit)] = pieceCode(p, bb.Kings&bit != 0)
+		}
+	}
+	var buf [32]BitMove
+	mobility := len(bb.Moves(buf[:0]))
+	bb.Turn = !bb.Turn
+	mobility -= len(bb.Moves(buf[:0]))
+	return English.terms(&occ, !bb.Turn, mobility)
+}
+
+// occupancy holds the piece on each square, see pieceCode.
+type occupancy [maxSquares + 1]int8
+
+// pieceCode is 1 for a man and 2 for a king, negative for White.
+func pieceCode(p Player, king bool) int8 {
+	c := int8(1)
+	if king {
+		c = 2
+	}
+	if p == White {
+		c = -c
+	}
+	return c
+}
+
+// terms counts the features of the pieces in occ. mobility is the number of
+// moves of turn less those of the opponent.
+func (v *Variant) terms(occ *occupancy, turn Player, mobility int) Terms {
+	g := v.geometry()
+	var t Terms
+	t[Mobility] = mobility
+	// The center is the middle half of the board both ways.
+	lo, hi := v.Size/4, v.Size-v.Size/4
+	for sq := 1; sq <= g.squares; sq++ {
+		c := occ[sq]
+		if c == 0 {
+			continue
+		}
+		p := Player(c > 0)
+		king := c == 2 || c == -2
+		sign := 1
+		if p != turn {
+			sign = -1
+		}
+		row, col := g.rows[sq], g.cols[sq]
+		t[Material] += sign
+		if row >= lo && row < hi && col >= lo && col < hi {
+			t[Center] += sign
+		}
+		if king {
+			t[Kings] += sign
+			continue
+		}
+		back := v.far(!p)
+		advanced := row - back
+		if advanced < 0 {
+			advanced = -advanced
+		}
+		t[Tempo] += sign * advanced
+		if row == back {
+			t[BackRank] += sign
+		}
+		if v.runaway(g, occ, p, row, col) {
+			t[Runaway] += sign
+		}
+	}
+	return t
+}
+
+// runaway reports whether no piece of p's opponent is in front of the man of
+// p at row and col, within the cone of squares it could reach.
+func (v *Variant) runaway(g *geometry, occ *occupancy, p Player, row, col int) bool {
+	step := forward(p)
+	far := v.far(p)
+	for d := 1; row+step*(d-1) != far; d++ {
+		for dc := -d; dc <= d; dc++ {
+			sq := g.square(row+step*d, col+dc)
+			if sq != 0 && occ[sq] != 0 && Player(occ[sq] > 0) != p {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package check
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestFeatures(t *testing.T) {
+	b, err := ParseFEN("W:W18,K30,29:B1,3,14,20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Terms{
+		Material: -1,
+		Kings:    1,
+		BackRank: -1,
+		Runaway:  -1,
+		Tempo:    -4,
+	}
+	if got := b.Features(); got != want {
+		t.Errorf("expected %v got %v", want, got)
+	}
+	bb, _ := b.Bitboard()
+	if got := bb.Features(); got != want {
+		t.Errorf("expected %v from the bitboard got %v", want, got)
+	}
+	w := English.DefaultWeights()
+	if score := w.Evaluate(want); score != -105 {
+		t.Errorf("expected -105 got %d", score)
+	}
+}
+
+// TestBitboardFeatures checks the bitboard counts the same as the board over
+// random games.
+func TestBitboardFeatures(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for game := 0; game < 20; game++ {
+		g := NewGame()
+		for !g.Over() {
+			b := g.Board()
+			bb, _ := b.Bitboard()
+			if want, got := b.Features(), bb.Features(); want != got {
+				t.Fatalf("%s: expected %v got %v", b.FEN(), want, got)
+			}
+			moves := g.LegalMoves()
+			if err := g.Apply(moves[r.Intn(len(moves))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestWeights(t *testing.T) {
+	w := English.DefaultWeights()
+	err := w.ReadWeights(strings.NewReader("# hand tuned\n\nkings 50\n  tempo -2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w[Kings] != 50 || w[Tempo] != -2 || w[Material] != 100 {
+		t.Errorf("expected kings and tempo to be replaced got %v", w)
+	}
+	var buf bytes.Buffer
+	if err := w.WriteWeights(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var read Weights
+	if err := read.ReadWeights(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if read != w {
+		t.Errorf("expected %v got %v", w, read)
+	}
+	for _, s := range []string{"kings", "queens 10", "kings ten", "kings 1 2"} {
+		if err := w.ReadWeights(strings.NewReader(s)); !errors.Is(err, ErrWeights) {
+			t.Errorf("%q: expected invalid weights got %v", s, err)
+		}
+	}
+	if Russian.DefaultWeights()[Kings] <= w[Kings] {
+		t.Error("expected flying kings to be worth more")
+	}
+}
@@ -0,0 +1,135 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// ErrFEN is wrapped by errors about malformed FEN.
+var ErrFEN = errors.New("invalid FEN")
+
+// ParseFEN returns the English draughts board described by s, see
+// Variant.ParseFEN.
+func ParseFEN(s string) (*Board, error) {
+	return English.ParseFEN(s)
+}
+
+// ParseFEN returns the board of v described by s in PDN notation, such as
+// W:W21,22,K5:B1,2,3. The first field is the player to move, the others list
+// the squares of each player with K marking kings. Squares may be given as
+// ranges like B1-12.
+func (v *Variant) ParseFEN(s string) (*Board, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(strings.Trim(strings.TrimSpace(s), `"`)), ".")
+	fields := strings.Split(s, ":")
+	turn, ok := parseColor(fields[0])
+	if !ok {
+		return nil, fmt.Errorf("%w: bad player to move %q", ErrFEN, fields[0])
+	}
+	b := &Board{PlayertTurn: bool(turn)}
+	if v != English {
+		b.Variant = v.Name
+	}
+	g := v.geometry()
+	seen := make(map[int32]bool)
+	for _, field := range fields[1:] {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		player, ok := parseColor(field[:1])
+		if !ok {
+			// Other fields, such as move counters, are not part of the position.
+			continue
+		}
+		for _, sq := range strings.Split(field[1:], ",") {
+			sq = strings.TrimSpace(sq)
+			if sq == "" {
+				continue
+			}
+			king := strings.HasPrefix(sq, "K")
+			from, to, err := parseSquares(strings.TrimPrefix(sq, "K"), g.squares)
+			if err != nil {
+				return nil, err
+			}
+			for pos := from; pos <= to; pos++ {
+				if seen[pos] {
+					return nil, fmt.Errorf("%w: square %d given twice", ErrFEN, pos)
+				}
+				seen[pos] = true
+				if !king && g.rows[pos] == v.far(player) {
+					return nil, fmt.Errorf("%w: man on crowning square %d", ErrFEN, pos)
+				}
+				b.Pieces = append(b.Pieces, &models.Piece{
+					Id:       int32(len(b.Pieces) + 1),
+					Player:   bool(player),
+					Position: pos,
+					King:     king,
+				})
+			}
+		}
+	}
+	b.sort_pieces()
+	b.resetPieces()
+	return b, nil
+}
+
+func parseColor(s string) (Player, bool) {
+	switch strings.TrimSpace(s) {
+	case "W":
+		return White, true
+	case "B":
+		return Black, true
+	}
+	return White, false
+}
+
+// parseSquares reads a square or a range of squares.
+func parseSquares(s string, squares int) (int32, int32, error) {
+	parts := strings.SplitN(s, "-", 2)
+	var n [2]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 1 || v > squares {
+			return 0, 0, fmt.Errorf("%w: bad square %q", ErrFEN, s)
+		}
+		n[i] = v
+	}
+	if len(parts) == 1 {
+		n[1] = n[0]
+	}
+	if n[1] < n[0] {
+		return 0, 0, fmt.Errorf("%w: bad range %q", ErrFEN, s)
+	}
+	return int32(n[0]), int32(n[1]), nil
+}
+
+// FEN returns the position on b in PDN notation. Squares are listed in
+// increasing order, without ranges.
+func (b *Board) FEN() string {
+	var s strings.Builder
+	s.WriteString(colorLetter(Player(b.PlayertTurn)))
+	for _, p := range []Player{White, Black} {
+		s.WriteString(":" + colorLetter(p))
+		for i, pos := range b.get_positions_by_player(p) {
+			if i > 0 {
+				s.WriteString(",")
+			}
+			if b.get_piece_by_position(pos).King {
+				s.WriteString("K")
+			}
+			s.WriteString(strconv.Itoa(int(pos)))
+		}
+	}
+	return s.String()
+}
+
+func colorLetter(p Player) string {
+	if p == Black {
+		return "B"
+	}
+	return "W"
+}
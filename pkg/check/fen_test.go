@@ -0,0 +1,87 @@
+package check
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFEN(t *testing.T) {
+	start := "B:W21,22,23,24,25,26,27,28,29,30,31,32:B1,2,3,4,5,6,7,8,9,10,11,12"
+	if fen := English.NewBoard().FEN(); fen != start {
+		t.Errorf("expected %s got %s", start, fen)
+	}
+	b, err := ParseFEN("B:W21-32:B1-12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !samePosition(b, English.NewBoard()) {
+		t.Error("expected ranges to give the starting position")
+	}
+
+	b, err = ParseFEN(`"W:W21,22,K5:B1,2,3."`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Player(b.PlayertTurn) != White {
+		t.Error("expected white to move")
+	}
+	if p := b.get_piece_by_position(5); p == nil || !p.King || Player(p.Player) != White {
+		t.Errorf("expected a white king on 5 got %v", p)
+	}
+	if fen := b.FEN(); fen != "W:WK5,21,22:B1,2,3" {
+		t.Errorf("unexpected FEN %s", fen)
+	}
+
+	b, err = International.ParseFEN("W:W46,K50:B1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.variant() != International {
+		t.Error("expected the board to keep its variant")
+	}
+}
+
+func TestFENErrors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"X:W21:B1",
+		"W:W33:B1",
+		"W:W21:B0",
+		"W:W21,21:B1",
+		"W:W21:B12-10",
+		"W:W1:B12",
+		"W:W21:B29",
+	} {
+		if _, err := ParseFEN(s); !errors.Is(err, ErrFEN) {
+			t.Errorf("%q: expected an invalid FEN got %v", s, err)
+		}
+	}
+}
+
+func TestNewGameFrom(t *testing.T) {
+	b, err := ParseFEN("W:WK14:B9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGameFrom(b)
+	if g.Setup() != "W:WK14:B9" {
+		t.Errorf("expected the setup to be kept got %q", g.Setup())
+	}
+	if err := g.Apply(g.LegalMoves()[0]); err != nil {
+		t.Fatal(err)
+	}
+	if g.Result() != WhiteWins || g.Reason() != NoPieces {
+		t.Errorf("expected white to win got %v by %v", g.Result(), g.Reason())
+	}
+
+	if g := NewGameFrom(English.NewBoard()); g.Setup() != "" {
+		t.Errorf("expected no setup for the starting position got %q", g.Setup())
+	}
+	b, err = ParseFEN("W:W:B1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := NewGameFrom(b); g.Result() != BlackWins {
+		t.Errorf("expected a game without white pieces to be over got %v", g.Result())
+	}
+}
@@ -0,0 +1,280 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+var (
+	// ErrIllegalMove is returned, wrapped in a *MoveError, when a move can not
+	// be applied to the current position.
+	ErrIllegalMove = errors.New("illegal move")
+
+	// ErrGameOver is returned when a move is applied to a finished game.
+	ErrGameOver = errors.New("game is over")
+
+	// ErrNotation is returned by ParseMove for text that is not a move.
+	ErrNotation = errors.New("moves are squares separated by - or x")
+)
+
+// MoveError describes why Move was rejected.
+type MoveError struct {
+	Move   *models.Move
+	Reason string
+}
+
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("%v %d-%d: %s", ErrIllegalMove, e.Move.GetFrom(), e.Move.GetTo(), e.Reason)
+}
+
+func (e *MoveError) Unwrap() error {
+	return ErrIllegalMove
+}
+
+func (p Player) String() string {
+	if p == Black {
+		return "black"
+	}
+	return "white"
+}
+
+// Game is a game of checkers played on a Board. It is the entry point for
+// other packages, everything on Board itself is an implementation detail.
+type Game struct {
+	board   *Board
+	history []undo
+	future  []*models.Move
+	result  Result
+	reason  Reason
+
+	rules DrawRules
+	// start is the hash of the position the game started from and setup its
+	// FEN when it is not the usual starting position.
+	start uint64
+	setup string
+	// key is the hash of the current position.
+	key uint64
+	// offer is the player who offered a draw, valid while offered is true.
+	offer   Player
+	offered bool
+
+	// pending are the ways the piece in the middle of a multiple jump can
+	// complete it, starting from its current square.
+	pending []*models.Move
+
+	// ballot is the opening the game was started with, see NewBallotGame,
+	// and fixed the number of its moves at the start of the history.
+	ballot *Ballot
+	fixed  int
+}
+
+// NewGame returns a game of English draughts with pieces on their starting
+// squares. Use Variant.NewGame for other rules.
+func NewGame() *Game {
+	return English.NewGame()
+}
+
+// NewGameFrom returns a game starting from the position on b, such as one
+// read with ParseFEN. The game takes b over.
+func NewGameFrom(b *Board) *Game {
+	v := b.variant()
+	g := &Game{board: b, rules: v.DrawRules, start: b.Hash()}
+	g.key = g.start
+	if fen := b.FEN(); fen != v.NewBoard().FEN() {
+		g.setup = fen
+	}
+	g.result, g.reason = b.result()
+	return g
+}
+
+// Setup returns the FEN of the position the game started from, or an empty
+// string when it started from the usual starting position.
+func (g *Game) Setup() string {
+	return g.setup
+}
+
+// Variant returns the rules the game is played by.
+func (g *Game) Variant() *Variant {
+	return g.board.variant()
+}
+
+// Board returns the board the game is played on. Callers must not modify it.
+func (g *Game) Board() *Board {
+	return g.board
+}
+
+// Turn returns the player who is to move.
+func (g *Game) Turn() Player {
+	return Player(g.board.PlayertTurn)
+}
+
+// Capturing reports whether the last move applied is the start of a capture
+// the piece has to complete.
+func (g *Game) Capturing() bool {
+	return len(g.pending) > 0
+}
+
+// LegalMoves returns the moves available to the player who is to move. When a
+// capture is possible only captures are returned, each one a complete
+// sequence of jumps. While a piece is in the middle of a multiple jump only
+// the rest of its sequences are returned.
+func (g *Game) LegalMoves() []*models.Move {
+	if len(g.pending) > 0 {
+		return g.pending
+	}
+	return g.board.get_possible_moves()
+}
+
+// Apply plays move for the player who is to move. A capture is given either
+// as a whole sequence, by its Path or by From and To when only one sequence
+// connects them, or one jump at a time. The returned error is a *MoveError
+// when the move is not legal in the current position.
+func (g *Game) Apply(move *models.Move) error {
+	if g.Over() {
+		return ErrGameOver
+	}
+	m, rest, err := g.match(move)
+	if err != nil {
+		return err
+	}
+	g.play(m, rest)
+	g.future = nil
+	return nil
+}
+
+// match returns the legal move that move describes. When move is only the
+// start of a capture it also returns the ways to complete it.
+func (g *Game) match(move *models.Move) (*models.Move, []*models.Move, error) {
+	legal := g.LegalMoves()
+	path := move.Path
+	if len(path) == 0 {
+		path = []int32{move.From, move.To}
+	}
+	var found []*models.Move
+	for _, m := range legal {
+		if equal(m.Path, path) {
+			return m, nil, nil
+		}
+		if len(path) == 2 && m.From == path[0] && m.To == path[1] {
+			found = append(found, m)
+		}
+	}
+	switch len(found) {
+	case 1:
+		return found[0], nil, nil
+	case 0:
+	default:
+		return nil, nil, &MoveError{Move: move, Reason: "more than one capture ends there, give the path"}
+	}
+	var start *models.Move
+	var rest []*models.Move
+	n := len(path) - 1
+	for _, m := range legal {
+		// Only captures are played a jump at a time, and a jump has two
+		// squares.
+		if n == 0 || len(m.Captures) == 0 {
+			continue
+		}
+		if len(path) < len(m.Path) && equal(m.Path[:len(path)], path) {
+			start = newMove(path, m.Captures[:n])
+			rest = append(rest, newMove(m.Path[n:], m.Captures[n:]))
+		}
+	}
+	if start != nil {
+		return start, rest, nil
+	}
+	return nil, nil, &MoveError{Move: move, Reason: g.explain(move, legal)}
+}
+
+// play performs m, which must be legal, and records it in the history. rest
+// are the ways to complete m when it is only the start of a capture.
+func (g *Game) play(m *models.Move, rest []*models.Move) {
+	b := g.board
+	u := b.record(m)
+	b.perform(m, len(rest) == 0)
+	g.pending = rest
+	g.key = b.rehash(g.key, u, len(rest) == 0)
+	u.hash = g.key
+	if u.further != 0 && len(g.history) > 0 {
+		g.history[len(g.history)-1].merge(u)
+	} else {
+		g.history = append(g.history, u)
+	}
+	g.offered = false
+	if len(rest) > 0 {
+		return
+	}
+	g.result, g.reason = b.result()
+	if g.result == Ongoing && g.drawn() {
+		g.result, g.reason = Draw, DrawRule
+	}
+}
+
+// Notation returns m in numeric notation, 11-15 for a move and 22x15x8 for a
+// capture.
+func Notation(m *models.Move) string {
+	path := m.GetPath()
+	if len(path) == 0 {
+		path = []int32{m.GetFrom(), m.GetTo()}
+	}
+	sep := "-"
+	if len(m.GetCaptures()) > 0 {
+		sep = "x"
+	}
+	parts := make([]string, len(path))
+	for i, sq := range path {
+		parts[i] = strconv.Itoa(int(sq))
+	}
+	return strings.Join(parts, sep)
+}
+
+// ParseMove reads a move written as in Notation, such as 11-15 or 22x15x8.
+func ParseMove(s string) (*models.Move, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == 'x' })
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("%w: %q", ErrNotation, s)
+	}
+	path := make([]int32, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrNotation, s)
+		}
+		path[i] = int32(n)
+	}
+	return &models.Move{From: path[0], To: path[len(path)-1], Path: path}, nil
+}
+
+func equal(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// explain returns a human readable reason why move is not among legal.
+func (g *Game) explain(move *models.Move, legal []*models.Move) string {
+	b := g.board
+	piece := b.get_piece_by_position(move.From)
+	switch {
+	case piece == nil:
+		return "no piece on square"
+	case Player(piece.Player) != g.Turn():
+		return "piece belongs to " + Player(piece.Player).String()
+	case b.PieceRequiringFurtherCaptureMoves != nil && b.PieceRequiringFurtherCaptureMoves.Id != piece.Id:
+		return "another piece must continue capturing"
+	case len(legal) > 0 && len(legal[0].Captures) > 0:
+		return "capture is mandatory"
+	default:
+		return "piece can not move there"
+	}
+}
@@ -0,0 +1,139 @@
+package check
+
+import "github.com/gernest/8x8/pkg/models"
+
+// undo records what a move changed so it can be taken back.
+type undo struct {
+	move *models.Move
+
+	// king is true when the moving piece was a king before the move.
+	king bool
+	// captured are the ids of the pieces jumped by the move and capturedAt
+	// the squares they stood on.
+	captured   []int32
+	capturedAt []int32
+
+	turn                   bool
+	previousMoveWasCapture bool
+	// further is the id of the piece that was in the middle of a multiple
+	// jump before the move, 0 when there was none.
+	further int32
+
+	// hash is the hash of the position after the move.
+	hash uint64
+}
+
+// record returns what is needed to take back move on b. It must be called
+// before the move is performed.
+func (b *Board) record(move *models.Move) undo {
+	piece := b.get_piece_by_position(move.From)
+	u := undo{
+		move:                   move,
+		king:                   piece.King,
+		turn:                   b.PlayertTurn,
+		previousMoveWasCapture: b.PreviousMoveWasCapture,
+	}
+	for _, pos := range move.Captures {
+		enemy := b.get_piece_by_position(pos)
+		u.captured = append(u.captured, enemy.Id)
+		u.capturedAt = append(u.capturedAt, pos)
+	}
+	if b.PieceRequiringFurtherCaptureMoves != nil {
+		u.further = b.PieceRequiringFurtherCaptureMoves.Id
+	}
+	return u
+}
+
+// merge extends u, which ended in the middle of a multiple jump, with next
+// which continues it.
+func (u *undo) merge(next undo) {
+	u.move = join(u.move, next.move)
+	u.captured = append(u.captured, next.captured...)
+	u.capturedAt = append(u.capturedAt, next.capturedAt...)
+	u.hash = next.hash
+}
+
+// unmove restores b to the position it had before u.move was performed.
+func (b *Board) unmove(u undo) {
+	piece := b.get_piece_by_position(u.move.To)
+	piece.Position = u.move.From
+	piece.King = u.king
+	for i, id := range u.captured {
+		enemy := b.PieceById[id]
+		enemy.Captured = false
+		enemy.Position = u.capturedAt[i]
+	}
+	b.PlayertTurn = u.turn
+	b.PreviousMoveWasCapture = u.previousMoveWasCapture
+	b.sort_pieces()
+	b.resetPieces()
+	b.PieceRequiringFurtherCaptureMoves = nil
+	if u.further != 0 {
+		b.PieceRequiringFurtherCaptureMoves = b.PieceById[u.further]
+	}
+}
+
+// History returns the moves played so far, oldest first. Jumps applied one at
+// a time are joined into a single move, the last one is incomplete while the
+// piece is still capturing.
+func (g *Game) History() []*models.Move {
+	ls := make([]*models.Move, len(g.history))
+	for i, u := range g.history {
+		ls[i] = u.move
+	}
+	return ls
+}
+
+// CanUndo reports whether there is a move to take back. The moves of the
+// game's ballot cannot be.
+func (g *Game) CanUndo() bool {
+	return len(g.history) > g.fixed
+}
+
+// CanRedo reports whether there is a taken back move to replay.
+func (g *Game) CanRedo() bool {
+	return len(g.future) > 0
+}
+
+// Undo takes back the last move, a multiple jump is taken back as a whole.
+// A game ended by resignation, timeout or agreement is resumed. It
+// returns false when there is nothing to undo.
+func (g *Game) Undo() bool {
+	if !g.CanUndo() {
+		return false
+	}
+	u := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+	g.board.unmove(u)
+	g.key = g.start
+	if len(g.history) > 0 {
+		g.key = g.history[len(g.history)-1].hash
+	}
+	g.pending = nil
+	g.result, g.reason = g.board.result()
+	g.future = append(g.future, u.move)
+	return true
+}
+
+// Redo replays the last move taken back with Undo. It returns false when
+// there is nothing to redo. Applying any other move discards the moves that
+// could be redone.
+func (g *Game) Redo() bool {
+	if !g.CanRedo() {
+		return false
+	}
+	m := g.future[len(g.future)-1]
+	future := g.future[:len(g.future)-1]
+	if err := g.Apply(m); err != nil {
+		return false
+	}
+	g.future = future
+	return true
+}
+
+// join returns the move made of first followed by next.
+func join(first, next *models.Move) *models.Move {
+	path := append(append([]int32{}, first.Path...), next.Path[1:]...)
+	caps := append(append([]int32{}, first.Captures...), next.Captures...)
+	return newMove(path, caps)
+}
@@ -0,0 +1,296 @@
+package check
+
+import "github.com/gernest/8x8/pkg/models"
+
+// Contents of a square during move generation.
+const (
+	empty int8 = iota
+	blackMan
+	blackKing
+	whiteMan
+	whiteKing
+	// taken is a piece captured by the move being made. It stays on the
+	// board until the move is complete, so it can not be jumped twice and
+	// nothing can land on it.
+	taken
+)
+
+func contents(p Player, king bool) int8 {
+	c := whiteMan
+	if p == Black {
+		c = blackMan
+	}
+	if king {
+		c++
+	}
+	return c
+}
+
+func owner(c int8) Player {
+	return Player(c == blackMan || c == blackKing)
+}
+
+func isKing(c int8) bool {
+	return c == blackKing || c == whiteKing
+}
+
+func crown(c int8) int8 {
+	if isKing(c) {
+		return c
+	}
+	return c + 1
+}
+
+// position is a board reduced to what move generation needs.
+type position struct {
+	v *Variant
+	g *geometry
+	// sq holds the contents of every square, indexed by square number.
+	sq   []int8
+	turn Player
+	// mover is the square of the piece in the middle of a multiple jump, 0
+	// when there is none.
+	mover int32
+}
+
+func (b *Board) position() *position {
+	v := b.variant()
+	g := v.geometry()
+	p := &position{
+		v:    v,
+		g:    g,
+		sq:   make([]int8, g.squares+1),
+		turn: Player(b.PlayertTurn),
+	}
+	for _, pc := range b.Pieces {
+		switch {
+		case pc.Position == 0:
+		case pc.Captured:
+			p.sq[pc.Position] = taken
+		default:
+			p.sq[pc.Position] = contents(Player(pc.Player), pc.King)
+		}
+	}
+	if b.PieceRequiringFurtherCaptureMoves != nil {
+		p.mover = b.PieceRequiringFurtherCaptureMoves.Position
+	}
+	return p
+}
+
+// moves returns the legal moves of the player to move.
+func (p *position) moves() []*models.Move {
+	var captures []*models.Move
+	for sq := int32(1); sq < int32(len(p.sq)); sq++ {
+		if p.own(sq) && (p.mover == 0 || p.mover == sq) {
+			captures = append(captures, p.captures(sq)...)
+		}
+	}
+	if len(captures) > 0 {
+		captures = p.prioritise(captures)
+		if !p.v.DistinctRoutes {
+			captures = dedupe(captures)
+		}
+		return captures
+	}
+	if p.mover != 0 {
+		return nil
+	}
+	var moves []*models.Move
+	for sq := int32(1); sq < int32(len(p.sq)); sq++ {
+		if p.own(sq) {
+			moves = append(moves, p.steps(sq)...)
+		}
+	}
+	return moves
+}
+
+// own reports whether a piece of the player to move stands on sq.
+func (p *position) own(sq int32) bool {
+	c := p.sq[sq]
+	return c != empty && c != taken && owner(c) == p.turn
+}
+
+// enemy reports whether sq holds a piece piece can capture.
+func (p *position) enemy(sq int32, piece int8) bool {
+	c := p.sq[sq]
+	return c != empty && c != taken && owner(c) != owner(piece)
+}
+
+// steps returns the moves without capture of the piece on from.
+func (p *position) steps(from int32) (moves []*models.Move) {
+	g := p.g
+	piece := p.sq[from]
+	king := isKing(piece)
+	for d, dir := range g.dirs {
+		if !king && !p.v.forwards(dir, owner(piece)) {
+			continue
+		}
+		for to := g.next[from][d]; to != 0 && p.sq[to] == empty; to = g.next[to][d] {
+			moves = append(moves, newMove([]int32{from, to}, nil))
+			if !king || !p.v.FlyingKings {
+				break
+			}
+		}
+	}
+	return
+}
+
+// captures returns every complete capture of the piece on from.
+func (p *position) captures(from int32) (moves []*models.Move) {
+	piece := p.sq[from]
+	// The piece leaves its square, a king may pass it again.
+	p.sq[from] = empty
+	p.jump(from, piece, -1, []int32{from}, nil, &moves)
+	p.sq[from] = piece
+	return
+}
+
+// jump adds to moves every capture that goes on from at, where piece arrived
+// along path capturing the pieces on caps, last moving in direction came. It
+// reports whether piece could capture anything from at.
+func (p *position) jump(at int32, piece int8, came int, path, caps []int32, moves *[]*models.Move) bool {
+	g := p.g
+	king := isKing(piece)
+	flying := king && p.v.FlyingKings
+	found := false
+	for d, dir := range g.dirs {
+		if !king && !p.v.MenCaptureBackwards && !p.v.forwards(dir, owner(piece)) {
+			continue
+		}
+		// Directions are listed so that opposite ones add up to len-1.
+		if p.v.RemoveImmediately && came >= 0 && d == len(g.dirs)-1-came {
+			continue
+		}
+		over := g.next[at][d]
+		for flying && over != 0 && p.sq[over] == empty {
+			over = g.next[over][d]
+		}
+		if over == 0 || !p.enemy(over, piece) {
+			continue
+		}
+		captured := p.sq[over]
+		if !king && p.v.MenCannotCaptureKings && isKing(captured) {
+			continue
+		}
+		p.sq[over] = taken
+		if p.v.RemoveImmediately {
+			p.sq[over] = empty
+		}
+		for to := g.next[over][d]; to != 0 && p.sq[to] == empty; to = g.next[to][d] {
+			found = true
+			nextPath := append(path[:len(path):len(path)], to)
+			nextCaps := append(caps[:len(caps):len(caps)], over)
+			next := piece
+			if !king && g.rows[to] == p.v.far(owner(piece)) {
+				switch p.v.Promotion {
+				case PromoteEndsMove:
+					*moves = append(*moves, newMove(nextPath, nextCaps))
+					continue
+				case PromoteAndContinue:
+					next = crown(piece)
+				}
+			}
+			if !p.jump(to, next, d, nextPath, nextCaps, moves) {
+				*moves = append(*moves, newMove(nextPath, nextCaps))
+			}
+			if !flying {
+				break
+			}
+		}
+		p.sq[over] = captured
+	}
+	return found
+}
+
+func newMove(path, caps []int32) *models.Move {
+	return &models.Move{
+		From:     path[0],
+		To:       path[len(path)-1],
+		Path:     path,
+		Captures: caps,
+	}
+}
+
+// dedupe drops the captures that take the same pieces between the same
+// squares as an earlier one.
+func dedupe(moves []*models.Move) []*models.Move {
+	keep := moves[:0]
+next:
+	for _, m := range moves {
+		for _, k := range keep {
+			if k.From == m.From && k.To == m.To && sameSquares(k.Captures, m.Captures) {
+				continue next
+			}
+		}
+		keep = append(keep, m)
+	}
+	return keep
+}
+
+// sameSquares reports whether a and b hold the same squares in any order.
+func sameSquares(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if x == y {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// prioritise returns the captures the variant's priorities allow.
+func (p *position) prioritise(moves []*models.Move) []*models.Move {
+	for _, pr := range p.v.Priorities {
+		best := -1 << 31
+		var keep []*models.Move
+		for _, m := range moves {
+			s := p.score(pr, m)
+			switch {
+			case s > best:
+				best = s
+				keep = append(keep[:0], m)
+			case s == best:
+				keep = append(keep, m)
+			}
+		}
+		moves = keep
+	}
+	return moves
+}
+
+// score rates capture m by priority pr, higher is preferred.
+func (p *position) score(pr Priority, m *models.Move) int {
+	switch pr {
+	case MostPieces:
+		return len(m.Captures)
+	case WithKing:
+		if isKing(p.sq[m.From]) {
+			return 1
+		}
+	case MostKings:
+		n := 0
+		for _, c := range m.Captures {
+			if isKing(p.sq[c]) {
+				n++
+			}
+		}
+		return n
+	case KingsFirst:
+		for i, c := range m.Captures {
+			if isKing(p.sq[c]) {
+				return -i
+			}
+		}
+		return -len(m.Captures)
+	}
+	return 0
+}
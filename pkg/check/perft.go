@@ -0,0 +1,71 @@
+package check
+
+import "github.com/gernest/8x8/pkg/models"
+
+// Perft returns the number of move sequences of length depth from the
+// position on b, counting a multiple jump as one move. Comparing it with
+// published numbers checks move generation. English positions are counted
+// on a Bitboard.
+func Perft(b *Board, depth int) uint64 {
+	if bb, ok := b.Bitboard(); ok {
+		return bb.Perft(depth)
+	}
+	return b.Clone().perft(depth)
+}
+
+// Perft is Perft for a Bitboard.
+func (bb Bitboard) Perft(depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+	var buf [32]BitMove
+	moves := bb.Moves(buf[:0])
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+	var n uint64
+	for _, m := range moves {
+		n += bb.Play(m).Perft(depth - 1)
+	}
+	return n
+}
+
+// perft counts with the generic move generator, playing the moves on b and
+// taking them back.
+func (b *Board) perft(depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+	moves := b.position().moves()
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+	var n uint64
+	for _, m := range moves {
+		u := b.record(m)
+		b.perform(m, true)
+		n += b.perft(depth - 1)
+		b.unmove(u)
+	}
+	return n
+}
+
+// MoveCount is the perft count below one move.
+type MoveCount struct {
+	Move  *models.Move
+	Nodes uint64
+}
+
+// Divide returns the perft count of depth below each legal move of b, which
+// helps finding where two move generators disagree.
+func Divide(b *Board, depth int) []MoveCount {
+	b = b.Clone()
+	var ls []MoveCount
+	for _, m := range b.get_possible_moves() {
+		u := b.record(m)
+		b.perform(m, true)
+		ls = append(ls, MoveCount{Move: m, Nodes: Perft(b, depth-1)})
+		b.unmove(u)
+	}
+	return ls
+}
@@ -0,0 +1,98 @@
+package check
+
+import "testing"
+
+// Published perft numbers from the starting position.
+var perftStart = []struct {
+	v      *Variant
+	counts []uint64
+	// short is how many depths are checked with -short.
+	short int
+}{
+	{English, []uint64{7, 49, 302, 1469, 7361, 36768, 179740, 845931, 3963680, 18391564}, 8},
+	{International, []uint64{9, 81, 658, 4265, 27117, 167140}, 5},
+}
+
+// Published perft numbers from other positions. Woldouby is full of multiple
+// captures, some of them taking the same pieces along different routes.
+var perftPositions = []struct {
+	name   string
+	v      *Variant
+	fen    string
+	counts []uint64
+	short  int
+}{
+	{
+		"woldouby", International,
+		"W:W25,27,28,30,32,33,34,35,37,38:B12,13,14,16,18,19,21,23,24,26",
+		[]uint64{6, 12, 30, 73, 215, 590, 1944, 6269, 22369, 88050}, 8,
+	},
+}
+
+func TestPerft(t *testing.T) {
+	for _, c := range perftStart {
+		t.Run(c.v.Name, func(t *testing.T) {
+			checkPerft(t, c.v.NewBoard(), c.counts, c.short)
+		})
+	}
+	for _, c := range perftPositions {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := c.v.ParseFEN(c.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkPerft(t, b, c.counts, c.short)
+		})
+	}
+}
+
+func checkPerft(t *testing.T, b *Board, counts []uint64, short int) {
+	t.Helper()
+	if testing.Short() {
+		counts = counts[:short]
+	}
+	for i, n := range counts {
+		if got := Perft(b, i+1); got != n {
+			t.Errorf("depth %d: expected %d got %d", i+1, n, got)
+		}
+	}
+}
+
+// TestPerftPositions counts English positions full of kings, forks and
+// promotions, which the starting position takes long to reach. Lacking
+// published numbers for them, the bitboard and the generic generator must
+// agree.
+func TestPerftPositions(t *testing.T) {
+	for _, fen := range []string{
+		"W:WK1,K4,K29,K32:BK14,K15,K18,K19",
+		"B:W18,19,26,27,K10:B1,2,3,K30",
+		"W:W21,22,23,24,25,26,K27:B5,6,7,8,9,10,K11,K15",
+		"B:W23,24,27,28,K31:B14,15,K19",
+		"W:W14,15,22,23,K30:B5,6,7,9,10,11,18,19",
+	} {
+		b, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for depth := 1; depth <= 6; depth++ {
+			if got, expect := Perft(b, depth), b.Clone().perft(depth); got != expect {
+				t.Errorf("%s depth %d: expected %d got %d", fen, depth, expect, got)
+			}
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	b := English.NewBoard()
+	var total uint64
+	ls := Divide(b, 4)
+	for _, c := range ls {
+		total += c.Nodes
+	}
+	if len(ls) != 7 || total != 1469 {
+		t.Errorf("expected 7 moves and 1469 nodes got %d and %d", len(ls), total)
+	}
+	if !samePosition(b, English.NewBoard()) {
+		t.Error("expected divide to leave the board alone")
+	}
+}
@@ -0,0 +1,127 @@
+package check
+
+import "errors"
+
+// ErrResultSet is returned when ending a game that is already over.
+var ErrResultSet = errors.New("game already has a result")
+
+// Result is the outcome of a game.
+type Result int
+
+const (
+	Ongoing Result = iota
+	WhiteWins
+	BlackWins
+	Draw
+)
+
+func (r Result) String() string {
+	switch r {
+	case WhiteWins:
+		return "white wins"
+	case BlackWins:
+		return "black wins"
+	case Draw:
+		return "draw"
+	default:
+		return "ongoing"
+	}
+}
+
+// Reason explains how a game reached its Result.
+type Reason int
+
+const (
+	NoReason Reason = iota
+	// NoPieces means the loser had no pieces left.
+	NoPieces
+	// Blocked means the loser had pieces but none of them could move.
+	Blocked
+	Resignation
+	Timeout
+	// Agreement means both players agreed to a draw.
+	Agreement
+	// DrawRule means the game was drawn by the move limit or by repetition,
+	// see DrawRules.
+	DrawRule
+)
+
+func (r Reason) String() string {
+	switch r {
+	case NoPieces:
+		return "no pieces"
+	case Blocked:
+		return "blocked"
+	case Resignation:
+		return "resignation"
+	case Timeout:
+		return "timeout"
+	case Agreement:
+		return "agreement"
+	case DrawRule:
+		return "draw rule"
+	default:
+		return ""
+	}
+}
+
+// winner returns the Result of p winning the game.
+func winner(p Player) Result {
+	if p == Black {
+		return BlackWins
+	}
+	return WhiteWins
+}
+
+// result decides the game from the position alone. The player to move loses
+// when they have no pieces left or none of their pieces can move.
+func (b *Board) result() (Result, Reason) {
+	turn := Player(b.PlayertTurn)
+	if len(b.player_pieces(turn)) == 0 {
+		return winner(!turn), NoPieces
+	}
+	if len(b.get_possible_moves()) == 0 {
+		return winner(!turn), Blocked
+	}
+	return Ongoing, NoReason
+}
+
+// Result returns the outcome of the game, Ongoing while it is being played.
+func (g *Game) Result() Result {
+	return g.result
+}
+
+// Reason returns how the game reached its result, NoReason while it is being
+// played.
+func (g *Game) Reason() Reason {
+	return g.reason
+}
+
+// Over reports whether the game has finished.
+func (g *Game) Over() bool {
+	return g.result != Ongoing
+}
+
+// Resign ends the game with p losing.
+func (g *Game) Resign(p Player) error {
+	return g.end(winner(!p), Resignation)
+}
+
+// Timeout ends the game with p losing because their clock ran out.
+func (g *Game) Timeout(p Player) error {
+	return g.end(winner(!p), Timeout)
+}
+
+// AgreeDraw ends the game in a draw both players agreed to. Use OfferDraw
+// and AcceptDraw when the players agree through the game.
+func (g *Game) AgreeDraw() error {
+	return g.end(Draw, Agreement)
+}
+
+func (g *Game) end(r Result, reason Reason) error {
+	if g.Over() {
+		return ErrResultSet
+	}
+	g.result, g.reason = r, reason
+	return nil
+}
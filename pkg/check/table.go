@@ -0,0 +1,109 @@
+package check
+
+import (
+	"math/bits"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// Bound tells how the score of a table Entry relates to the position's value.
+type Bound uint8
+
+const (
+	// NoBound marks an empty entry.
+	NoBound Bound = iota
+	// Exact scores are the value of the position.
+	Exact
+	// Lower scores are at most the value, the search failed high.
+	Lower
+	// Upper scores are at least the value, the search failed low.
+	Upper
+)
+
+// Entry is what a Table remembers about a position.
+type Entry struct {
+	// Key is the position's hash, see Board.Hash.
+	Key   uint64
+	Score int32
+	// Move identifies the best move found, see MoveKey.
+	Move  int32
+	Depth int8
+	Bound Bound
+	// age is the search that stored the entry.
+	age uint8
+}
+
+// MoveKey returns a key for m that tells it apart from the other moves of the
+// position. The low 16 bits are the first square times 256 plus the last,
+// the bits above set one bit per captured square modulo 15. Captures taking
+// many pieces can still share a key, callers must not pick between moves
+// with the same key.
+func MoveKey(m *models.Move) int32 {
+	var caps int32
+	for _, sq := range m.Captures {
+		caps |= 1 << (sq % 15)
+	}
+	return caps<<16 | m.From<<8 | m.To
+}
+
+// Key is MoveKey for a BitMove, the bits above the squares hold the start of
+// its Route instead of its captures.
+func (m BitMove) Key() int32 {
+	k := int32(bits.TrailingZeros32(m.From)+1)<<8 | int32(bits.TrailingZeros32(m.To)+1)
+	return k | int32(m.Route&0x7fff)<<16
+}
+
+// Table is a fixed size transposition table indexed by position hashes. A
+// search stores what it learned about positions so that reaching one again,
+// through another order of moves or a later search, costs nothing. It is
+// not safe for concurrent use.
+type Table struct {
+	entries []Entry
+	mask    uint64
+	age     uint8
+}
+
+// NewTable returns a table with room for size entries, rounded down to a
+// power of two.
+func NewTable(size int) *Table {
+	n := 1
+	for n*2 <= size {
+		n *= 2
+	}
+	return &Table{entries: make([]Entry, n), mask: uint64(n - 1)}
+}
+
+// Len returns the number of entries the table has room for.
+func (t *Table) Len() int {
+	return len(t.entries)
+}
+
+// Probe returns the entry stored for key.
+func (t *Table) Probe(key uint64) (Entry, bool) {
+	e := t.entries[key&t.mask]
+	return e, e.Bound != NoBound && e.Key == key
+}
+
+// NewSearch tells the table a new search starts. Entries of earlier searches
+// are kept but give way to new ones.
+func (t *Table) NewSearch() {
+	t.age++
+}
+
+// Store remembers e. An entry for another position is only replaced by one
+// searched at least as deep, unless it is from an earlier search.
+func (t *Table) Store(e Entry) {
+	slot := &t.entries[e.Key&t.mask]
+	if slot.Bound != NoBound && slot.Key != e.Key && slot.age == t.age && slot.Depth > e.Depth {
+		return
+	}
+	e.age = t.age
+	*slot = e
+}
+
+// Clear empties the table.
+func (t *Table) Clear() {
+	for i := range t.entries {
+		t.entries[i] = Entry{}
+	}
+}
@@ -0,0 +1,416 @@
+package check
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+var (
+	// ErrTablebase is returned when reading a file that is not a tablebase.
+	ErrTablebase = errors.New("check: invalid tablebase")
+
+	// ErrTablebaseSize is returned when asking for a tablebase with more
+	// pieces than can be generated.
+	ErrTablebaseSize = errors.New("check: too many pieces for a tablebase")
+)
+
+// MaxTablebasePieces is the most pieces a tablebase can be generated for.
+// Five pieces have about 412 million indices, a byte each for the values and
+// as much again for the moves pending while generating, plus four bytes for
+// every position queued: well over a gigabyte.
+const MaxTablebasePieces = 4
+
+// Outcome is the value of a position with perfect play, for the player to
+// move.
+type Outcome int8
+
+const (
+	Lost Outcome = iota - 1
+	Drawn
+	Won
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Won:
+		return "win"
+	case Lost:
+		return "loss"
+	default:
+		return "draw"
+	}
+}
+
+// Tablebase knows the outcome of every English draughts position with a few
+// pieces, and how many moves it takes to get there.
+//
+// Positions are indexed by the number of pieces k on the board: the rank of
+// the set of squares they stand on among all sets of k squares, then two bits
+// per piece in square order telling its colour and whether it is a king, then
+// the player to move. Each position takes a byte, 0 for a draw or for a
+// position that cannot happen, otherwise the number of plies to the end of
+// the game plus one. Wins take an odd number of plies and losses an even one.
+type Tablebase struct {
+	// Pieces is the most pieces of the positions in the tablebase.
+	Pieces int
+	// slices holds the positions with k pieces at index k.
+	slices [][]uint8
+}
+
+// binomial[n][k] is n choose k.
+var binomial [33][MaxTablebasePieces + 2]int
+
+func init() {
+	for n := range binomial {
+		binomial[n][0] = 1
+		for k := 1; k < len(binomial[n]) && k <= n; k++ {
+			binomial[n][k] = binomial[n-1][k-1] + binomial[n-1][k]
+		}
+	}
+}
+
+// sliceSize returns the number of indices of positions with k pieces.
+func sliceSize(k int) int {
+	return binomial[32][k] << (2 * k) * 2
+}
+
+// tablebaseIndex returns the index of bb among the positions with as many
+// pieces.
+func tablebaseIndex(bb Bitboard) int {
+	rank, kinds := 0, 0
+	i := 0
+	for set := bb.Black | bb.White; set != 0; set &= set - 1 {
+		bit := set & -set
+		sq := bits.TrailingZeros32(bit)
+		rank += binomial[sq][i+1]
+		if bb.White&bit != 0 {
+			kinds |= 1 << (2 * i)
+		}
+		if bb.Kings&bit != 0 {
+			kinds |= 2 << (2 * i)
+		}
+		i++
+	}
+	index := (rank<<(2*i) | kinds) * 2
+	if bb.Turn == White {
+		index++
+	}
+	return index
+}
+
+// tablebasePosition returns the position of index among those with k pieces.
+// It reports false when the position cannot happen: a side has no pieces or
+// a man stands on the row it would have been crowned on.
+func tablebasePosition(index, k int) (Bitboard, bool) {
+	var bb Bitboard
+	if index&1 == 1 {
+		bb.Turn = White
+	} else {
+		bb.Turn = Black
+	}
+	index >>= 1
+	kinds := index & (1<<(2*k) - 1)
+	rank := index >> (2 * k)
+	var squares [MaxTablebasePieces]uint32
+	sq := 31
+	for i := k - 1; i >= 0; i-- {
+		for binomial[sq][i+1] > rank {
+			sq--
+		}
+		rank -= binomial[sq][i+1]
+		squares[i] = 1 << sq
+		sq--
+	}
+	for i := 0; i < k; i++ {
+		bit := squares[i]
+		kind := kinds >> (2 * i) & 3
+		if kind&1 == 1 {
+			bb.White |= bit
+		} else {
+			bb.Black |= bit
+		}
+		if kind&2 == 2 {
+			bb.Kings |= bit
+		}
+	}
+	men := ^bb.Kings
+	ok := bb.Black != 0 && bb.White != 0 &&
+		bb.Black&men&crownRow(Black) == 0 && bb.White&men&crownRow(White) == 0
+	return bb, ok
+}
+
+// decode turns a stored byte into an outcome and a distance in plies.
+func decode(v uint8) (Outcome, int) {
+	switch {
+	case v == 0:
+		return Drawn, 0
+	case v%2 == 0:
+		return Won, int(v) - 1
+	default:
+		return Lost, int(v) - 1
+	}
+}
+
+// Probe returns the outcome of b for the player to move and the number of
+// plies until the game ends with best play. It reports false when b is not in
+// the tablebase.
+func (tb *Tablebase) Probe(b *Board) (Outcome, int, bool) {
+	bb, ok := b.Bitboard()
+	if !ok {
+		return Drawn, 0, false
+	}
+	return tb.ProbeBitboard(bb)
+}
+
+// ProbeBitboard is Probe for a Bitboard.
+func (tb *Tablebase) ProbeBitboard(bb Bitboard) (Outcome, int, bool) {
+	own, enemy := bb.pieces(bb.Turn), bb.pieces(!bb.Turn)
+	switch k := bits.OnesCount32(own | enemy); {
+	case own == 0:
+		return Lost, 0, true
+	case enemy == 0:
+		// The game ended before the opponent's turn.
+		return Won, 0, true
+	case k > tb.Pieces:
+		return Drawn, 0, false
+	default:
+		o, d := decode(tb.slices[k][tablebaseIndex(bb)])
+		return o, d, true
+	}
+}
+
+// GenerateTablebase works out every position with up to pieces pieces by
+// retrograde analysis, from the positions where the game is over backwards.
+// progress, when set, is called as the positions with each number of pieces
+// are done.
+func GenerateTablebase(pieces int, progress func(pieces int)) (*Tablebase, error) {
+	if pieces < 2 || pieces > MaxTablebasePieces {
+		return nil, fmt.Errorf("%w: %d", ErrTablebaseSize, pieces)
+	}
+	tb := &Tablebase{slices: make([][]uint8, pieces+1)}
+	for k := 2; k <= pieces; k++ {
+		tb.generate(k)
+		tb.Pieces = k
+		if progress != nil {
+			progress(k)
+		}
+	}
+	return tb, nil
+}
+
+// generate fills the slice of positions with k pieces. Captures lead to
+// positions with fewer pieces, which are known already, so the positions
+// with a capture are settled first. The rest only lead to positions with k
+// pieces: going back from those settled, in order of distance, a position
+// is won as soon as one move leads to a lost position and lost once all its
+// moves lead to won ones. Whatever is left is drawn.
+func (tb *Tablebase) generate(k int) {
+	n := sliceSize(k)
+	values := make([]uint8, n)
+	tb.slices[k] = values
+	// pending is the number of moves of each unsettled position not yet known
+	// to lead to a won position.
+	pending := make([]uint8, n)
+	var queue [][]int32
+	settle := func(i int, v int) {
+		if v > 255 {
+			panic("check: tablebase distance does not fit in a byte")
+		}
+		values[i] = uint8(v)
+		d := v - 1
+		for len(queue) <= d {
+			queue = append(queue, nil)
+		}
+		queue[d] = append(queue[d], int32(i))
+	}
+	var buf [64]BitMove
+	for i := 0; i < n; i++ {
+		bb, ok := tablebasePosition(i, k)
+		if !ok {
+			continue
+		}
+		moves := bb.Moves(buf[:0])
+		switch {
+		case len(moves) == 0:
+			settle(i, 1)
+		case moves[0].Captures != 0:
+			if v := tb.best(bb, moves); v != 0 {
+				settle(i, int(v))
+			}
+		default:
+			pending[i] = uint8(len(moves))
+		}
+	}
+	var back []Bitboard
+	for d := 0; d < len(queue); d++ {
+		for j := 0; j < len(queue[d]); j++ {
+			i := int(queue[d][j])
+			bb, _ := tablebasePosition(i, k)
+			lost := values[i]%2 == 1
+			back = unmoves(bb, back[:0])
+			for _, prev := range back {
+				p := tablebaseIndex(prev)
+				if values[p] != 0 || pending[p] == 0 {
+					continue
+				}
+				if lost {
+					settle(p, d+2)
+					continue
+				}
+				pending[p]--
+				if pending[p] == 0 {
+					settle(p, d+2)
+				}
+			}
+		}
+		queue[d] = nil
+	}
+}
+
+// best returns the stored value of bb, whose moves are all captures and lead
+// to positions already in the tablebase.
+func (tb *Tablebase) best(bb Bitboard, moves []BitMove) uint8 {
+	win, loss, draw := -1, -1, false
+	for _, m := range moves {
+		o, d, _ := tb.ProbeBitboard(bb.Play(m))
+		switch o {
+		case Lost:
+			if win < 0 || d+1 < win {
+				win = d + 1
+			}
+		case Won:
+			if d+1 > loss {
+				loss = d + 1
+			}
+		default:
+			draw = true
+		}
+	}
+	switch {
+	case win >= 0:
+		return uint8(win + 1)
+	case draw:
+		return 0
+	default:
+		return uint8(loss + 1)
+	}
+}
+
+// unmoves appends the positions from which the player who just moved reached
+// bb with a move that is not a capture.
+func unmoves(bb Bitboard, prev []Bitboard) []Bitboard {
+	mover := !bb.Turn
+	empty := ^(bb.Black | bb.White)
+	var buf [64]BitMove
+	for set := bb.pieces(mover); set != 0; set &= set - 1 {
+		to := set & -set
+		king := bb.Kings&to != 0
+		// origins are the squares the piece may have come from and whether
+		// it was a man there.
+		var origins [8]struct {
+			from uint32
+			man  bool
+		}
+		n := 0
+		if king {
+			for _, d := range kingDirs {
+				if from := step(to, d) & empty; from != 0 {
+					origins[n].from, origins[n].man = from, false
+					n++
+				}
+			}
+		}
+		if !king || to&crownRow(mover) != 0 {
+			// A man came from behind, perhaps to be crowned.
+			for _, d := range dirs(!mover, false) {
+				if from := step(to, d) & empty; from != 0 {
+					origins[n].from, origins[n].man = from, true
+					n++
+				}
+			}
+		}
+		for _, o := range origins[:n] {
+			p := bb
+			p.Turn = mover
+			if mover == Black {
+				p.Black = p.Black&^to | o.from
+			} else {
+				p.White = p.White&^to | o.from
+			}
+			p.Kings &^= to
+			if !o.man {
+				p.Kings |= o.from
+			}
+			// The move was only legal when there was no capture to make.
+			if moves := p.Moves(buf[:0]); len(moves) > 0 && moves[0].Captures != 0 {
+				continue
+			}
+			prev = append(prev, p)
+		}
+	}
+	return prev
+}
+
+// tablebaseMagic starts a tablebase file.
+const tablebaseMagic = "8x8TB\x01"
+
+// WriteTo writes tb to w, compressed.
+func (tb *Tablebase) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	z := gzip.NewWriter(cw)
+	if _, err := io.WriteString(z, tablebaseMagic); err != nil {
+		return cw.n, err
+	}
+	if _, err := z.Write([]byte{byte(tb.Pieces)}); err != nil {
+		return cw.n, err
+	}
+	for k := 2; k <= tb.Pieces; k++ {
+		if _, err := z.Write(tb.slices[k]); err != nil {
+			return cw.n, err
+		}
+	}
+	err := z.Close()
+	return cw.n, err
+}
+
+// ReadTablebase reads a tablebase written by WriteTo.
+func ReadTablebase(r io.Reader) (*Tablebase, error) {
+	z, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTablebase, err)
+	}
+	header := make([]byte, len(tablebaseMagic)+1)
+	if _, err := io.ReadFull(z, header); err != nil || string(header[:len(tablebaseMagic)]) != tablebaseMagic {
+		return nil, ErrTablebase
+	}
+	pieces := int(header[len(tablebaseMagic)])
+	if pieces < 2 || pieces > MaxTablebasePieces {
+		return nil, fmt.Errorf("%w: %d pieces", ErrTablebase, pieces)
+	}
+	tb := &Tablebase{Pieces: pieces, slices: make([][]uint8, pieces+1)}
+	for k := 2; k <= pieces; k++ {
+		tb.slices[k] = make([]uint8, sliceSize(k))
+		if _, err := io.ReadFull(z, tb.slices[k]); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTablebase, err)
+		}
+	}
+	if _, err := z.Read(make([]byte, 1)); err != io.EOF {
+		return nil, fmt.Errorf("%w: trailing data", ErrTablebase)
+	}
+	return tb, nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,95 @@
+package check
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestTablebase checks every position of a three piece tablebase against
+// the positions its moves lead to.
+func TestTablebase(t *testing.T) {
+	tb, err := GenerateTablebase(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [64]BitMove
+	for k := 2; k <= 3; k++ {
+		for i := 0; i < sliceSize(k); i++ {
+			bb, ok := tablebasePosition(i, k)
+			if !ok {
+				continue
+			}
+			if j := tablebaseIndex(bb); j != i {
+				t.Fatalf("index %d comes back as %d", i, j)
+			}
+			got, gotD, _ := tb.ProbeBitboard(bb)
+			want, wantD := Lost, 0
+			moves := bb.Moves(buf[:0])
+			for _, m := range moves {
+				o, d, _ := tb.ProbeBitboard(bb.Play(m))
+				d++
+				switch {
+				case o == Lost && (want != Won || d < wantD):
+					want, wantD = Won, d
+				case o == Drawn && want == Lost:
+					want, wantD = Drawn, 0
+				case o == Won && want == Lost && d > wantD:
+					wantD = d
+				}
+			}
+			if got != want || gotD != wantD {
+				t.Fatalf("%s: expected %v in %d got %v in %d", bb.Board().FEN(), want, wantD, got, gotD)
+			}
+		}
+	}
+}
+
+func TestTablebaseProbe(t *testing.T) {
+	tb, err := GenerateTablebase(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		fen  string
+		want Outcome
+	}{
+		{"W:WK1,K2:BK32", Won},
+		{"B:WK1,K2:BK32", Lost},
+		// Black's king is caught in the single corner.
+		{"B:WK11:BK4", Lost},
+		{"W:WK1:BK32", Drawn},
+	} {
+		b, err := ParseFEN(c.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o, d, ok := tb.Probe(b)
+		if !ok || o != c.want {
+			t.Errorf("%s: expected %v got %v in %d (%v)", c.fen, c.want, o, d, ok)
+		}
+	}
+	if _, _, ok := tb.Probe(English.NewBoard()); ok {
+		t.Error("expected the starting position not to be in the tablebase")
+	}
+
+	var buf bytes.Buffer
+	if _, err := tb.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadTablebase(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 2; k <= 3; k++ {
+		if !bytes.Equal(read.slices[k], tb.slices[k]) {
+			t.Errorf("expected the %d piece positions to be read back", k)
+		}
+	}
+	if _, err := ReadTablebase(bytes.NewReader(buf.Bytes()[:buf.Len()/2])); !errors.Is(err, ErrTablebase) {
+		t.Errorf("expected a truncated file to be invalid got %v", err)
+	}
+	if _, err := GenerateTablebase(MaxTablebasePieces+1, nil); !errors.Is(err, ErrTablebaseSize) {
+		t.Errorf("expected too many pieces got %v", err)
+	}
+}
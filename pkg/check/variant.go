@@ -0,0 +1,394 @@
+package check
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// Promotion decides what happens to a man that reaches the far row while it
+// is capturing.
+type Promotion int
+
+const (
+	// PromoteEndsMove crowns the man and ends the move.
+	PromoteEndsMove Promotion = iota
+	// PromoteAndContinue crowns the man, which goes on capturing as a king.
+	PromoteAndContinue
+	// PromoteAtEnd lets the man go on capturing as a man. It is only crowned
+	// when the move ends on the far row.
+	PromoteAtEnd
+)
+
+// Priority narrows down which of the available captures a player may choose.
+type Priority int
+
+const (
+	// MostPieces requires capturing as many pieces as possible.
+	MostPieces Priority = iota
+	// WithKing requires capturing with a king when a king can capture.
+	WithKing
+	// MostKings requires capturing as many kings as possible.
+	MostKings
+	// KingsFirst requires capturing a king as early in the move as possible.
+	KingsFirst
+)
+
+// Variant is a set of rules checkers can be played by.
+type Variant struct {
+	// Name identifies the variant, see LookupVariant.
+	Name string
+	// GameType is the PDN GameType tag of the variant.
+	GameType int
+
+	// Size is the number of rows and columns of the board.
+	Size int
+	// Orthogonal puts pieces on every square of the board, moving along rows
+	// and columns instead of diagonals. Men move forward and sideways.
+	Orthogonal bool
+	// Rows is the number of rows each player fills with men at the start.
+	Rows int
+	// SkipRows is the number of rows nearest to each player left empty at
+	// the start.
+	SkipRows int
+	// First is the player who makes the first move.
+	First Player
+
+	// MenCaptureBackwards allows men to capture in all directions, they
+	// always move forward.
+	MenCaptureBackwards bool
+	// MenCannotCaptureKings protects kings from being captured by men.
+	MenCannotCaptureKings bool
+	// FlyingKings lets kings move and capture along a whole line.
+	FlyingKings bool
+	// RemoveImmediately takes captured pieces off the board as they are
+	// jumped instead of at the end of the move. A capturing piece may then
+	// not turn back along the line it came from.
+	RemoveImmediately bool
+	// Promotion applies when a man reaches the far row in a capture.
+	Promotion Promotion
+	// Priorities are applied in order to the captures available to the
+	// player, an empty list leaves the choice free.
+	Priorities []Priority
+	// DistinctRoutes keeps captures that take the same pieces between the
+	// same squares along different routes as different moves. Otherwise
+	// only one of them is offered.
+	DistinctRoutes bool
+
+	// DrawRules are the draw rules a new game of the variant starts with.
+	DrawRules DrawRules
+}
+
+// English is English draughts, also known as American checkers.
+var English = &Variant{
+	Name:           "english",
+	GameType:       21,
+	Size:           8,
+	Rows:           3,
+	First:          Black,
+	Promotion:      PromoteEndsMove,
+	DistinctRoutes: true,
+	DrawRules:      DefaultDrawRules,
+}
+
+// International is international draughts played on a 10x10 board.
+var International = &Variant{
+	Name:                "international",
+	GameType:            20,
+	Size:                10,
+	Rows:                4,
+	First:               White,
+	MenCaptureBackwards: true,
+	FlyingKings:         true,
+	Promotion:           PromoteAtEnd,
+	Priorities:          []Priority{MostPieces},
+	DrawRules: DrawRules{
+		MoveLimit:   25,
+		Repetitions: 3,
+	},
+}
+
+// Russian is Russian draughts. A man crowned while capturing goes on
+// capturing as a king and the player may choose any capture.
+var Russian = &Variant{
+	Name:                "russian",
+	GameType:            25,
+	Size:                8,
+	Rows:                3,
+	First:               White,
+	MenCaptureBackwards: true,
+	FlyingKings:         true,
+	Promotion:           PromoteAndContinue,
+	DrawRules: DrawRules{
+		MoveLimit:   15,
+		Repetitions: 3,
+	},
+}
+
+// Brazilian is international draughts played on an 8x8 board.
+var Brazilian = &Variant{
+	Name:                "brazilian",
+	GameType:            26,
+	Size:                8,
+	Rows:                3,
+	First:               White,
+	MenCaptureBackwards: true,
+	FlyingKings:         true,
+	Promotion:           PromoteAtEnd,
+	Priorities:          []Priority{MostPieces},
+	DrawRules: DrawRules{
+		MoveLimit:   20,
+		Repetitions: 3,
+	},
+}
+
+// Pool is American pool checkers. Men capture backwards and kings fly, but
+// the player may choose any capture.
+var Pool = &Variant{
+	Name:                "pool",
+	GameType:            23,
+	Size:                8,
+	Rows:                3,
+	First:               Black,
+	MenCaptureBackwards: true,
+	FlyingKings:         true,
+	Promotion:           PromoteAtEnd,
+	DrawRules:           DefaultDrawRules,
+}
+
+// Italian is Italian draughts. Men can not capture kings and captures are
+// chosen by the most pieces, then capturing with a king, then the most kings
+// and then capturing kings first.
+var Italian = &Variant{
+	Name:                  "italian",
+	GameType:              22,
+	Size:                  8,
+	Rows:                  3,
+	First:                 White,
+	MenCannotCaptureKings: true,
+	Promotion:             PromoteEndsMove,
+	Priorities:            []Priority{MostPieces, WithKing, MostKings, KingsFirst},
+	DrawRules:             DefaultDrawRules,
+}
+
+// Spanish is Spanish draughts, with flying kings and captures chosen by the
+// most pieces and then the most kings.
+var Spanish = &Variant{
+	Name:        "spanish",
+	GameType:    24,
+	Size:        8,
+	Rows:        3,
+	First:       White,
+	FlyingKings: true,
+	Promotion:   PromoteEndsMove,
+	Priorities:  []Priority{MostPieces, MostKings},
+	DrawRules:   DefaultDrawRules,
+}
+
+// Czech is Czech draughts, with flying kings that must capture whenever a
+// king can.
+var Czech = &Variant{
+	Name:        "czech",
+	GameType:    29,
+	Size:        8,
+	Rows:        3,
+	First:       White,
+	FlyingKings: true,
+	Promotion:   PromoteEndsMove,
+	Priorities:  []Priority{WithKing},
+	DrawRules:   DefaultDrawRules,
+}
+
+// Turkish is Turkish draughts, played on all 64 squares with pieces moving
+// orthogonally. Captured pieces are removed as they are jumped and the
+// player must capture as many pieces as possible.
+var Turkish = &Variant{
+	Name:              "turkish",
+	GameType:          30,
+	Size:              8,
+	Orthogonal:        true,
+	Rows:              2,
+	SkipRows:          1,
+	First:             White,
+	FlyingKings:       true,
+	RemoveImmediately: true,
+	Promotion:         PromoteAtEnd,
+	Priorities:        []Priority{MostPieces},
+	DrawRules: DrawRules{
+		Repetitions: 3,
+	},
+}
+
+// Variants are the variants LookupVariant knows about.
+var Variants = []*Variant{
+	English,
+	International,
+	Russian,
+	Brazilian,
+	Pool,
+	Italian,
+	Spanish,
+	Czech,
+	Turkish,
+}
+
+// LookupVariant returns the variant called name, English draughts when name is
+// empty and nil when there is no such variant.
+func LookupVariant(name string) *Variant {
+	if name == "" {
+		return English
+	}
+	for _, v := range Variants {
+		if strings.EqualFold(v.Name, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// Squares returns the number of squares pieces can stand on.
+func (v *Variant) Squares() int {
+	return v.geometry().squares
+}
+
+// Coordinates returns the row and column of square, counted from the top left
+// corner of the board. White's men are crowned on row 0.
+func (v *Variant) Coordinates(square int32) (row, col int) {
+	g := v.geometry()
+	return g.rows[square], g.cols[square]
+}
+
+// NewBoard returns a board set up for the start of a game of v. Black fills
+// the lowest numbered squares and White the highest.
+func (v *Variant) NewBoard() *Board {
+	g := v.geometry()
+	b := &Board{
+		PlayertTurn: bool(v.First),
+	}
+	if v != English {
+		b.Variant = v.Name
+	}
+	var id int32
+	for sq := 1; sq <= g.squares; sq++ {
+		var player Player
+		switch row := g.rows[sq]; {
+		case row >= v.SkipRows && row < v.SkipRows+v.Rows:
+			player = Black
+		case row < v.Size-v.SkipRows && row >= v.Size-v.SkipRows-v.Rows:
+			player = White
+		default:
+			continue
+		}
+		id++
+		b.Pieces = append(b.Pieces, &models.Piece{
+			Id:       id,
+			Player:   bool(player),
+			Position: int32(sq),
+		})
+	}
+	b.resetPieces()
+	return b
+}
+
+// NewGame returns a game of v with pieces on their starting squares.
+func (v *Variant) NewGame() *Game {
+	return NewGameFrom(v.NewBoard())
+}
+
+// forward returns the row direction p's men move in.
+func forward(p Player) int {
+	if p == Black {
+		return 1
+	}
+	return -1
+}
+
+// forwards reports whether p's men move in direction dir.
+func (v *Variant) forwards(dir [2]int, p Player) bool {
+	return dir[0] == forward(p) || v.Orthogonal && dir[0] == 0
+}
+
+// far returns the row p's men are crowned on.
+func (v *Variant) far(p Player) int {
+	if p == Black {
+		return v.Size - 1
+	}
+	return 0
+}
+
+// geometry numbers the squares of a board and knows how they connect.
+type geometry struct {
+	size    int
+	squares int
+	// rows and cols are the coordinates of each square, 0 is not a square.
+	rows, cols []int
+	// dirs are the directions pieces move in, as row and column steps.
+	dirs [][2]int
+	// next is the square one step away in each direction, 0 off the board.
+	next [][]int32
+	// at is the square at each row and column, row by row, 0 for light
+	// squares.
+	at []int32
+}
+
+// square returns the square at row and column, 0 when there is none.
+func (g *geometry) square(row, col int) int32 {
+	if row < 0 || row >= g.size || col < 0 || col >= g.size {
+		return 0
+	}
+	return g.at[row*g.size+col]
+}
+
+type geometryKey struct {
+	size       int
+	orthogonal bool
+}
+
+var geometries sync.Map
+
+// geometry returns the squares of the board v is played on. The squares are
+// numbered row by row starting at the top. Unless v is orthogonal only the
+// dark squares are used and the first row starts with a light square.
+func (v *Variant) geometry() *geometry {
+	key := geometryKey{size: v.Size, orthogonal: v.Orthogonal}
+	if g, ok := geometries.Load(key); ok {
+		return g.(*geometry)
+	}
+	g := newGeometry(key)
+	geometries.Store(key, g)
+	return g
+}
+
+func newGeometry(key geometryKey) *geometry {
+	size := key.size
+	g := &geometry{
+		size: size,
+		rows: []int{0},
+		cols: []int{0},
+		at:   make([]int32, size*size),
+		dirs: [][2]int{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}},
+	}
+	if key.orthogonal {
+		g.dirs = [][2]int{{-1, 0}, {0, -1}, {0, 1}, {1, 0}}
+	}
+	for r := 0; r < size; r++ {
+		for c := 0; c < size; c++ {
+			if !key.orthogonal && (r+c)%2 == 0 {
+				continue
+			}
+			g.squares++
+			g.rows = append(g.rows, r)
+			g.cols = append(g.cols, c)
+			g.at[r*size+c] = int32(g.squares)
+		}
+	}
+	g.next = make([][]int32, g.squares+1)
+	for sq := 1; sq <= g.squares; sq++ {
+		g.next[sq] = make([]int32, len(g.dirs))
+		for d, dir := range g.dirs {
+			g.next[sq][d] = g.square(g.rows[sq]+dir[0], g.cols[sq]+dir[1])
+		}
+	}
+	return g
+}
@@ -0,0 +1,251 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// testGame returns a game of v with pieces on the given squares, kings are
+// given as negative squares.
+func testGame(v *Variant, turn Player, black, white []int32) *Game {
+	b := &Board{PlayertTurn: bool(turn)}
+	if v != English {
+		b.Variant = v.Name
+	}
+	var id int32
+	add := func(p Player, squares []int32) {
+		for _, sq := range squares {
+			id++
+			piece := &models.Piece{Id: id, Player: bool(p), Position: sq}
+			if sq < 0 {
+				piece.Position, piece.King = -sq, true
+			}
+			b.Pieces = append(b.Pieces, piece)
+		}
+	}
+	add(Black, black)
+	add(White, white)
+	b.sort_pieces()
+	b.resetPieces()
+	return NewGameFrom(b)
+}
+
+func paths(moves []*models.Move) [][]int32 {
+	ls := make([][]int32, len(moves))
+	for i, m := range moves {
+		ls[i] = m.Path
+	}
+	return ls
+}
+
+func TestInternational(t *testing.T) {
+	t.Run("start", func(t *testing.T) {
+		g := International.NewGame()
+		if g.Turn() != White {
+			t.Errorf("expected white to move first")
+		}
+		if n := len(g.Board().UncapturedPieces); n != 40 {
+			t.Errorf("expected 40 pieces got %d", n)
+		}
+		if n := len(g.LegalMoves()); n != 9 {
+			t.Errorf("expected 9 opening moves got %d", n)
+		}
+	})
+	t.Run("flying king", func(t *testing.T) {
+		g := testGame(International, White, []int32{1}, []int32{-46})
+		if n := len(g.LegalMoves()); n != 9 {
+			t.Errorf("expected the king to reach 9 squares got %d", n)
+		}
+	})
+	t.Run("majority capture", func(t *testing.T) {
+		g := testGame(International, White, []int32{17, 27, 28}, []int32{32})
+		moves := g.LegalMoves()
+		if len(moves) != 1 || !equal(moves[0].Path, []int32{32, 21, 12}) {
+			t.Errorf("expected only the double capture got %v", paths(moves))
+		}
+	})
+	t.Run("no promotion passing the far row", func(t *testing.T) {
+		g := testGame(International, White, []int32{7, 8, 50}, []int32{11})
+		if err := g.Apply(&models.Move{From: 11, To: 13}); err != nil {
+			t.Fatal(err)
+		}
+		if g.Board().get_piece_by_position(13).King {
+			t.Error("expected the man to stay a man")
+		}
+	})
+}
+
+func TestPromotionDuringCapture(t *testing.T) {
+	// A white man on 11 jumps 7 and reaches the far row on 2. Only a king can
+	// go on to jump 9 from there.
+	black, white := []int32{7, 9, 32}, []int32{11}
+	cases := []struct {
+		v    *Variant
+		path []int32
+	}{
+		{English, []int32{11, 2}},
+		{Russian, []int32{11, 2, 13}},
+		{Brazilian, []int32{11, 2}},
+		{Pool, []int32{11, 2}},
+	}
+	for _, c := range cases {
+		t.Run(c.v.Name, func(t *testing.T) {
+			g := testGame(c.v, White, black, white)
+			moves := g.LegalMoves()
+			if len(moves) != 1 || !equal(moves[0].Path, c.path) {
+				t.Fatalf("expected %v got %v", c.path, paths(moves))
+			}
+			if err := g.Apply(moves[0]); err != nil {
+				t.Fatal(err)
+			}
+			if !g.Board().get_piece_by_position(moves[0].To).King {
+				t.Error("expected the man to be crowned")
+			}
+		})
+	}
+}
+
+func TestCaptureChoice(t *testing.T) {
+	// The white man on 26 can capture 22 and 14, or only 23.
+	black, white := []int32{14, 22, 23}, []int32{26}
+	cases := []struct {
+		v     *Variant
+		moves int
+	}{
+		{Russian, 2},
+		{Brazilian, 1},
+		{Pool, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.v.Name, func(t *testing.T) {
+			g := testGame(c.v, White, black, white)
+			if moves := g.LegalMoves(); len(moves) != c.moves {
+				t.Errorf("expected %d captures got %v", c.moves, paths(moves))
+			}
+		})
+	}
+}
+
+func TestMenCaptureBackwards(t *testing.T) {
+	black, white := []int32{18}, []int32{14, 32}
+	for _, v := range []*Variant{Russian, Brazilian, Pool} {
+		g := testGame(v, Black, black, white)
+		moves := g.LegalMoves()
+		if len(moves) != 1 || !equal(moves[0].Path, []int32{18, 9}) {
+			t.Errorf("%s: expected the man to capture backwards got %v", v.Name, paths(moves))
+		}
+	}
+	g := testGame(English, Black, black, white)
+	if moves := g.LegalMoves(); len(moves[0].Captures) != 0 {
+		t.Errorf("english: expected men not to capture backwards got %v", paths(moves))
+	}
+}
+
+func TestMenCannotCaptureKings(t *testing.T) {
+	// The white man on 22 faces a black king on 17.
+	black, white := []int32{-17}, []int32{22}
+	g := testGame(Italian, White, black, white)
+	for _, m := range g.LegalMoves() {
+		if len(m.Captures) > 0 {
+			t.Errorf("man captured a king: %v", m.Path)
+		}
+	}
+	g = testGame(Spanish, White, black, white)
+	if got := paths(g.LegalMoves()); !reflect.DeepEqual(got, [][]int32{{22, 13}}) {
+		t.Errorf("expected the king to be captured got %v", got)
+	}
+}
+
+func TestCapturePriorities(t *testing.T) {
+	cases := []struct {
+		name         string
+		v            *Variant
+		black, white []int32
+		expect       [][]int32
+	}{
+		// The king on 24 and the man on 26 can each capture one man.
+		{"with king", Italian, []int32{19, 22}, []int32{-24, 26}, [][]int32{{24, 15}}},
+		// The man on 28 can capture a king, the man on 26 a man.
+		{"most kings", Spanish, []int32{22, -24}, []int32{26, 28}, [][]int32{{28, 19}}},
+		// The king on 30 can capture a king and then a man, or the other way
+		// round.
+		{"kings first", Italian, []int32{17, -19, -25, 26}, []int32{-30}, [][]int32{{30, 21, 14}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := testGame(c.v, White, c.black, c.white)
+			if got := paths(g.LegalMoves()); !reflect.DeepEqual(got, c.expect) {
+				t.Errorf("expected %v got %v", c.expect, got)
+			}
+		})
+	}
+}
+
+func TestCzech(t *testing.T) {
+	// The man on 25 can capture two pieces, the king on 30 only one but a
+	// king must capture when it can.
+	g := testGame(Czech, White, []int32{15, 22, 26}, []int32{25, -30})
+	moves := g.LegalMoves()
+	if len(moves) == 0 {
+		t.Fatal("expected captures")
+	}
+	for _, m := range moves {
+		if m.From != 30 {
+			t.Errorf("expected the king to capture got %v", m.Path)
+		}
+	}
+}
+
+func TestTurkish(t *testing.T) {
+	t.Run("start", func(t *testing.T) {
+		g := Turkish.NewGame()
+		if n := len(g.Board().Pieces); n != 32 {
+			t.Errorf("expected 32 pieces got %d", n)
+		}
+		if n := len(g.LegalMoves()); n != 8 {
+			t.Errorf("expected 8 moves got %d", n)
+		}
+	})
+	t.Run("sideways capture", func(t *testing.T) {
+		// Forward over 28 then sideways over 21 beats taking 35 alone.
+		g := testGame(Turkish, White, []int32{21, 28, 35}, []int32{36})
+		start := g.Board().Clone()
+		expect := [][]int32{{36, 20, 22}}
+		if got := paths(g.LegalMoves()); !reflect.DeepEqual(got, expect) {
+			t.Errorf("expected %v got %v", expect, got)
+		}
+		if err := g.Apply(&models.Move{From: 36, To: 20}); err != nil {
+			t.Fatal(err)
+		}
+		if g.Board().get_piece_by_position(28) != nil {
+			t.Error("expected the captured piece to be removed at once")
+		}
+		if !g.Undo() {
+			t.Fatal("expected to undo the jump")
+		}
+		if !samePosition(g.Board(), start) {
+			t.Error("expected undo to put the captured piece back")
+		}
+	})
+	t.Run("flying king", func(t *testing.T) {
+		g := testGame(Turkish, White, []int32{8}, []int32{-57})
+		if n := len(g.LegalMoves()); n != 14 {
+			t.Errorf("expected 14 moves got %d", n)
+		}
+	})
+	t.Run("no turning back", func(t *testing.T) {
+		// The king on 36 may not capture 38 and come back over 34.
+		g := testGame(Turkish, White, []int32{34, 38}, []int32{-36})
+		moves := g.LegalMoves()
+		if len(moves) != 3 {
+			t.Errorf("expected 3 captures got %v", paths(moves))
+		}
+		for _, m := range moves {
+			if len(m.Captures) != 1 {
+				t.Errorf("turned back: %v", m.Path)
+			}
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package check
+
+// maxSquares is the most squares a variant's board has.
+const maxSquares = 64
+
+// Zobrist keys: a random number for each piece on each square and one for
+// Black to move. A position hashes to the xor of the keys of its pieces, so
+// moves update it in a few operations.
+var (
+	pieceKeys [maxSquares + 1][taken]uint64
+	turnKey   uint64
+)
+
+func init() {
+	// splitmix64 with a fixed seed, hashes must not change between runs.
+	seed := uint64(0x8a8c0ffee)
+	next := func() uint64 {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+		z = (z ^ z>>27) * 0x94d049bb133111eb
+		return z ^ z>>31
+	}
+	for sq := range pieceKeys {
+		for c := blackMan; c < taken; c++ {
+			pieceKeys[sq][c] = next()
+		}
+	}
+	turnKey = next()
+}
+
+func pieceKey(sq int32, p Player, king bool) uint64 {
+	return pieceKeys[sq][contents(p, king)]
+}
+
+// Hash returns the Zobrist hash of the position: the pieces on the board and
+// the player to move. Equal positions have equal hashes, and the hash of an
+// English position equals the one of its Bitboard. Game.Key keeps it up to
+// date as moves are played.
+func (b *Board) Hash() uint64 {
+	var h uint64
+	for _, p := range b.UncapturedPieces {
+		h ^= pieceKey(p.Position, Player(p.Player), p.King)
+	}
+	if b.PlayertTurn {
+		h ^= turnKey
+	}
+	return h
+}
+
+// rehash returns h, the hash of b before the move of u, updated for the move.
+// It must be called after the move is performed, final tells whether it
+// ended the turn.
+func (b *Board) rehash(h uint64, u undo, final bool) uint64 {
+	piece := b.get_piece_by_position(u.move.To)
+	p := Player(piece.Player)
+	h ^= pieceKey(u.move.From, p, u.king) ^ pieceKey(u.move.To, p, piece.King)
+	for i, id := range u.captured {
+		c := b.PieceById[id]
+		h ^= pieceKey(u.capturedAt[i], Player(c.Player), c.King)
+	}
+	if final {
+		h ^= turnKey
+	}
+	return h
+}
+
+// Key returns the Zobrist hash of the current position, see Board.Hash.
+func (g *Game) Key() uint64 {
+	return g.key
+}
+
+// Hash returns the Zobrist hash of bb, the same as Board.Hash.
+func (bb Bitboard) Hash() uint64 {
+	var h uint64
+	for _, p := range []Player{Black, White} {
+		for set := bb.pieces(p); set != 0; set &= set - 1 {
+			bit := set & -set
+			h ^= pieceKey(square(bit), p, bb.Kings&bit != 0)
+		}
+	}
+	if bb.Turn == Black {
+		h ^= turnKey
+	}
+	return h
+}
+
+// NextHash returns the hash of the position after m given h, the hash of bb.
+func (bb Bitboard) NextHash(h uint64, m BitMove) uint64 {
+	king := bb.Kings&m.From != 0
+	h ^= pieceKey(square(m.From), bb.Turn, king)
+	h ^= pieceKey(square(m.To), bb.Turn, king || m.To&crownRow(bb.Turn) != 0)
+	for set := m.Captures; set != 0; set &= set - 1 {
+		bit := set & -set
+		h ^= pieceKey(square(bit), !bb.Turn, bb.Kings&bit != 0)
+	}
+	return h ^ turnKey
+}
@@ -0,0 +1,105 @@
+package check
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// TestKey plays random games jump by jump, checking the incremental key
+// against a hash of the whole board after every move and undo.
+func TestKey(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, v := range Variants {
+		t.Run(v.Name, func(t *testing.T) {
+			g := v.NewGame()
+			for ply := 0; ply < 150 && !g.Over(); ply++ {
+				moves := g.LegalMoves()
+				m := moves[r.Intn(len(moves))]
+				if len(m.Path) > 2 {
+					// Only the first jump, the rest is played next time.
+					m = &models.Move{Path: m.Path[:2]}
+				}
+				if err := g.Apply(m); err != nil {
+					t.Fatal(err)
+				}
+				if g.Key() != g.Board().Hash() {
+					t.Fatalf("ply %d: key %x does not match hash %x", ply, g.Key(), g.Board().Hash())
+				}
+				if r.Intn(4) == 0 {
+					g.Undo()
+					if g.Key() != g.Board().Hash() {
+						t.Fatalf("ply %d: key %x does not match hash %x after undo", ply, g.Key(), g.Board().Hash())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBitboardHash(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	bb, _ := English.NewBoard().Bitboard()
+	h := bb.Hash()
+	for ply := 0; ply < 200; ply++ {
+		if want := bb.Board().Hash(); h != want {
+			t.Fatalf("ply %d: expected %x got %x", ply, want, h)
+		}
+		moves := bb.Moves(nil)
+		if len(moves) == 0 {
+			break
+		}
+		m := moves[r.Intn(len(moves))]
+		h = bb.NextHash(h, m)
+		bb = bb.Play(m)
+	}
+}
+
+func TestHashTranspositions(t *testing.T) {
+	play := func(moves ...int32) uint64 {
+		g := NewGame()
+		for i := 0; i < len(moves); i += 2 {
+			if err := g.Apply(&models.Move{From: moves[i], To: moves[i+1]}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return g.Key()
+	}
+	a := play(11, 15, 24, 20, 12, 16)
+	b := play(12, 16, 24, 20, 11, 15)
+	if a != b {
+		t.Error("expected the same position reached in another order to hash the same")
+	}
+	if c := play(11, 15, 24, 20); c == a {
+		t.Error("expected different positions to hash differently")
+	}
+}
+
+func TestTable(t *testing.T) {
+	tt := NewTable(1000)
+	if tt.Len() != 512 {
+		t.Fatalf("expected 512 entries got %d", tt.Len())
+	}
+	if _, ok := tt.Probe(42); ok {
+		t.Error("expected an empty table")
+	}
+	tt.Store(Entry{Key: 42, Score: 7, Depth: 5, Bound: Exact})
+	if e, ok := tt.Probe(42); !ok || e.Score != 7 {
+		t.Errorf("expected the stored entry got %v %v", e, ok)
+	}
+	// 42+512 uses the same slot, a shallower entry does not replace it.
+	tt.Store(Entry{Key: 42 + 512, Depth: 2, Bound: Lower})
+	if _, ok := tt.Probe(42); !ok {
+		t.Error("expected the deeper entry to stay")
+	}
+	tt.NewSearch()
+	tt.Store(Entry{Key: 42 + 512, Depth: 2, Bound: Lower})
+	if _, ok := tt.Probe(42 + 512); !ok {
+		t.Error("expected an entry of an earlier search to be replaced")
+	}
+	tt.Clear()
+	if _, ok := tt.Probe(42 + 512); ok {
+		t.Error("expected a cleared table")
+	}
+}
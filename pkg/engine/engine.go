@@ -0,0 +1,409 @@
+// Package engine is a computer opponent. It looks for the best move of a
+// position with an iterative deepening alpha-beta search.
+package engine
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// ErrNoMoves is returned when searching a position without legal moves.
+var ErrNoMoves = errors.New("engine: no legal moves")
+
+const (
+	// Win is the score of a won position. A win in n moves scores Win-n, a
+	// loss in n moves n-Win.
+	Win = 1 << 20
+
+	infinity = Win + 1
+	// maxPly bounds how deep the search goes, captures included.
+	maxPly = 128
+
+	// TableSize is the number of positions an engine's table remembers
+	// unless it is given another one.
+	TableSize = 1 << 18
+)
+
+// Limits bound a search. At least one move ahead is always searched.
+type Limits struct {
+	// Depth is the number of moves to look ahead, 0 for as deep as time
+	// allows. Captures are always followed to the end.
+	Depth int
+	// Time is how long to search, 0 for no limit.
+	Time time.Duration
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	// Move is the best move found and PV the moves both players are expected
+	// to play from there on, starting with Move.
+	Move *models.Move
+	PV   []*models.Move
+	// Score is the value of the position for the player to move, in
+	// hundredths of a man, see Win.
+	Score int
+	// Depth is the deepest search completed.
+	Depth int
+	Nodes uint64
+	Time  time.Duration
+}
+
+// Engine searches positions. It is not safe for concurrent use, give each
+// game its own Engine.
+type Engine struct {
+	Limits Limits
+	// Info, when set, is called with the result of every completed depth.
+	Info func(Result)
+	// Table remembers positions from one search to the next, New makes one
+	// of TableSize entries.
+	Table *check.Table
+	// Weights, when set, replace the default weights of the evaluation.
+	Weights *check.Weights
+	// Tablebase, when set, gives the value of the positions it has instead
+	// of searching them.
+	Tablebase *check.Tablebase
+	// Book, when set, gives the moves of the positions it has. Those are
+	// played without searching.
+	Book *check.Book
+	// Rand chooses between book moves, New seeds it with the time.
+	Rand *rand.Rand
+
+	ctx       context.Context
+	deadline  time.Time
+	iteration int
+	stopped   bool
+	nodes     uint64
+
+	// pv is the triangular table of principal variations, pv[ply] holds the
+	// best line found from ply onwards. Moves are indices, see position.
+	pv    [maxPly][maxPly]int
+	pvLen [maxPly]int
+	// last is the principal variation of the previous iteration, searched
+	// first.
+	last    []int
+	killers [maxPly][2]int32
+}
+
+// New returns an engine searching within l.
+func New(l Limits) *Engine {
+	return &Engine{
+		Limits: l,
+		Table:  check.NewTable(TableSize),
+		Rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Search returns the best move of b within l.
+func Search(ctx context.Context, b *check.Board, l Limits) (*Result, error) {
+	return New(l).Search(ctx, b)
+}
+
+// Play searches the position of g and applies the best move found.
+func (e *Engine) Play(ctx context.Context, g *check.Game) (*Result, error) {
+	r, err := e.Search(ctx, g.Board())
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Apply(r.Move); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Search returns the best move of b. It stops at the engine's limits or when
+// ctx is done, with the result of the deepest search it completed.
+func (e *Engine) Search(ctx context.Context, b *check.Board) (*Result, error) {
+	v := check.LookupVariant(b.Variant)
+	if v == nil {
+		v = check.English
+	}
+	w := e.Weights
+	if w == nil {
+		d := v.DefaultWeights()
+		w = &d
+	}
+	root := newPosition(b, w)
+	n, _ := root.generate()
+	if n == 0 {
+		return nil, ErrNoMoves
+	}
+	if e.Book != nil && e.Book.Variant == v {
+		if e.Rand == nil {
+			e.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		if m, ok := e.Book.Pick(b, e.Rand); ok {
+			return &Result{Move: m, PV: []*models.Move{m}}, nil
+		}
+	}
+	start := time.Now()
+	e.ctx = ctx
+	e.deadline = time.Time{}
+	if e.Limits.Time > 0 {
+		e.deadline = start.Add(e.Limits.Time)
+	}
+	if e.Table == nil {
+		e.Table = check.NewTable(TableSize)
+	}
+	e.Table.NewSearch()
+	e.stopped = false
+	e.nodes = 0
+	e.last = nil
+	e.killers = [maxPly][2]int32{}
+	depth := e.Limits.Depth
+	if depth <= 0 || depth >= maxPly/2 {
+		depth = maxPly / 2
+	}
+	var best *Result
+	for e.iteration = 1; e.iteration <= depth; e.iteration++ {
+		if e.iteration > 1 && e.expired() {
+			break
+		}
+		score := e.negamax(root, e.iteration, 0, -infinity, infinity, true)
+		if e.stopped {
+			break
+		}
+		e.last = append(e.last[:0], e.pv[0][:e.pvLen[0]]...)
+		best = e.result(root, score, start)
+		if e.Info != nil {
+			e.Info(*best)
+		}
+		if n == 1 || score >= Win-maxPly || score <= maxPly-Win {
+			// A forced move or a forced result, looking deeper changes nothing.
+			break
+		}
+	}
+	return best, nil
+}
+
+func (e *Engine) result(root position, score int, start time.Time) *Result {
+	r := &Result{
+		Score: score,
+		Depth: e.iteration,
+		Nodes: e.nodes,
+		Time:  time.Since(start),
+	}
+	p := root
+	for _, i := range e.last {
+		p.generate()
+		r.PV = append(r.PV, p.move(i))
+		p = p.child(i)
+	}
+	// Cut offs from the table end the line early, the table knows how it
+	// goes on.
+	for len(r.PV) < e.iteration {
+		t, ok := e.Table.Probe(p.hash())
+		if !ok {
+			break
+		}
+		i := find(p, t.Move)
+		if i < 0 {
+			break
+		}
+		r.PV = append(r.PV, p.move(i))
+		p = p.child(i)
+	}
+	r.Move = r.PV[0]
+	return r
+}
+
+// find returns the index of the move of p with the given key, -1 when there
+// is none. Long captures can share a key, rather than guess find returns -1
+// for them too.
+func find(p position, key int32) int {
+	n, _ := p.generate()
+	found := -1
+	for i := 0; i < n; i++ {
+		if p.key(i) == key {
+			if found >= 0 {
+				return -1
+			}
+			found = i
+		}
+	}
+	return found
+}
+
+// probe returns the score of p from the tablebase. The root is always
+// searched, to have a move to play.
+func (e *Engine) probe(p position, ply int) (int, bool) {
+	if e.Tablebase == nil || ply == 0 {
+		return 0, false
+	}
+	o, d, ok := p.probe(e.Tablebase)
+	switch {
+	case !ok:
+		return 0, false
+	case o == check.Won:
+		return Win - ply - d, true
+	case o == check.Lost:
+		return ply + d - Win, true
+	default:
+		return 0, true
+	}
+}
+
+// negamax returns the score of p for the player to move, searching depth
+// moves ahead. onPV is true while p is on the previous principal variation.
+func (e *Engine) negamax(p position, depth, ply, alpha, beta int, onPV bool) int {
+	e.nodes++
+	e.pvLen[ply] = 0
+	n, capture := p.generate()
+	if n == 0 {
+		return ply - Win
+	}
+	if score, ok := e.probe(p, ply); ok {
+		return score
+	}
+	switch {
+	case depth <= 0 && !capture, ply >= maxPly-1:
+		// Captures are forced, the position is only quiet enough to be
+		// evaluated when there are none.
+		return p.evaluate()
+	case e.poll():
+		return 0
+	}
+	if depth < 0 {
+		// Following captures is the same search at any depth below 1.
+		depth = 0
+	}
+	h := p.hash()
+	t, ok := e.Table.Probe(h)
+	if ok && ply > 0 && !onPV && int(t.Depth) >= depth {
+		score := fromTable(int(t.Score), ply)
+		switch {
+		case t.Bound == check.Exact,
+			t.Bound == check.Lower && score >= beta,
+			t.Bound == check.Upper && score <= alpha:
+			return score
+		}
+	}
+	start := alpha
+	best, bestMove := -infinity, int32(0)
+	for _, i := range e.order(p, n, ply, onPV, t.Move) {
+		score := -e.negamax(p.child(i), depth-1, ply+1, -beta, -alpha, onPV && e.first(ply) == i)
+		if e.stopped {
+			return 0
+		}
+		if score > best {
+			best, bestMove = score, p.key(i)
+		}
+		if score > alpha {
+			alpha = score
+			e.pv[ply][0] = i
+			copy(e.pv[ply][1:], e.pv[ply+1][:e.pvLen[ply+1]])
+			e.pvLen[ply] = e.pvLen[ply+1] + 1
+		}
+		if alpha >= beta {
+			if !capture {
+				e.killer(ply, p.key(i))
+			}
+			break
+		}
+	}
+	bound := check.Exact
+	switch {
+	case best <= start:
+		bound = check.Upper
+	case best >= beta:
+		bound = check.Lower
+	}
+	e.Table.Store(check.Entry{
+		Key:   h,
+		Score: int32(toTable(best, ply)),
+		Move:  bestMove,
+		Depth: int8(depth),
+		Bound: bound,
+	})
+	return best
+}
+
+// toTable returns score as stored in the table. Wins and losses are counted
+// from the position stored rather than from the root.
+func toTable(score, ply int) int {
+	switch {
+	case score >= Win-maxPly:
+		return score + ply
+	case score <= maxPly-Win:
+		return score - ply
+	}
+	return score
+}
+
+func fromTable(score, ply int) int {
+	switch {
+	case score >= Win-maxPly:
+		return score - ply
+	case score <= maxPly-Win:
+		return score + ply
+	}
+	return score
+}
+
+// first returns the move of the previous principal variation at ply, -1 past
+// its end.
+func (e *Engine) first(ply int) int {
+	if ply < len(e.last) {
+		return e.last[ply]
+	}
+	return -1
+}
+
+// order returns the order to search the moves of p in: the previous
+// principal variation, the best move from the table, then moves that caused
+// cut offs at the same ply, then the rest.
+func (e *Engine) order(p position, n, ply int, onPV bool, best int32) []int {
+	ls := make([]int, 0, n)
+	if onPV {
+		if i := e.first(ply); i >= 0 && i < n {
+			ls = append(ls, i)
+		}
+	}
+	for _, k := range []int32{best, e.killers[ply][0], e.killers[ply][1]} {
+		if k == 0 {
+			continue
+		}
+		if i := find(p, k); i >= 0 && !contains(ls, i) {
+			ls = append(ls, i)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if !contains(ls, i) {
+			ls = append(ls, i)
+		}
+	}
+	return ls
+}
+
+func contains(ls []int, i int) bool {
+	for _, v := range ls {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Engine) killer(ply int, key int32) {
+	if e.killers[ply][0] != key {
+		e.killers[ply][1] = e.killers[ply][0]
+		e.killers[ply][0] = key
+	}
+}
+
+// poll reports whether the search must stop. The first iteration always
+// completes so there is a move to return.
+func (e *Engine) poll() bool {
+	if !e.stopped && e.iteration > 1 && e.nodes&1023 == 0 {
+		e.stopped = e.expired()
+	}
+	return e.stopped
+}
+
+func (e *Engine) expired() bool {
+	return e.ctx.Err() != nil || !e.deadline.IsZero() && time.Now().After(e.deadline)
+}
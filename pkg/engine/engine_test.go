@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+)
+
+func board(t *testing.T, v *check.Variant, fen string) *check.Board {
+	t.Helper()
+	b, err := v.ParseFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestWinningCapture(t *testing.T) {
+	r, err := Search(context.Background(), board(t, check.English, "W:W18:B14"), Limits{Depth: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Score != Win-1 {
+		t.Errorf("expected a win in one move got %d", r.Score)
+	}
+	if !reflect.DeepEqual(r.Move.Path, []int32{18, 9}) {
+		t.Errorf("expected 18x9 got %v", r.Move.Path)
+	}
+}
+
+func TestPrincipalVariation(t *testing.T) {
+	for _, v := range []*check.Variant{check.English, check.Russian} {
+		t.Run(v.Name, func(t *testing.T) {
+			g := v.NewGame()
+			r, err := Search(context.Background(), g.Board(), Limits{Depth: 4})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Depth != 4 || len(r.PV) < 4 {
+				t.Fatalf("expected a depth 4 line got depth %d and %d moves", r.Depth, len(r.PV))
+			}
+			if r.Move != r.PV[0] {
+				t.Error("expected the best move to start the line")
+			}
+			for _, m := range r.PV {
+				if err := g.Apply(m); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	start := time.Now()
+	r, err := Search(context.Background(), check.English.NewBoard(), Limits{Time: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the search to stop after 100ms, it took %v", elapsed)
+	}
+	if r.Depth < 2 {
+		t.Errorf("expected more than one depth in 100ms got %d", r.Depth)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, err = Search(ctx, check.English.NewBoard(), Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Depth != 1 || r.Move == nil {
+		t.Errorf("expected a cancelled search to still find a move got depth %d", r.Depth)
+	}
+}
+
+func TestNoMoves(t *testing.T) {
+	_, err := Search(context.Background(), board(t, check.English, "W:W:B1"), Limits{Depth: 2})
+	if !errors.Is(err, ErrNoMoves) {
+		t.Errorf("expected no moves got %v", err)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	// Black is half way through 9x18x27.
+	g := check.NewGameFrom(board(t, check.English, "B:W14,23,32:B9"))
+	if err := g.Apply(&models.Move{From: 9, To: 18}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(Limits{Depth: 2}).Play(context.Background(), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Move.Path, []int32{18, 27}) {
+		t.Errorf("expected the jump to be finished got %v", r.Move.Path)
+	}
+	if g.Turn() != check.White {
+		t.Errorf("expected white to move got %v", g.Turn())
+	}
+}
+
+func TestTableReuse(t *testing.T) {
+	e := New(Limits{Depth: 8})
+	b := check.English.NewBoard()
+	first, err := e.Search(context.Background(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := e.Search(context.Background(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Nodes >= first.Nodes {
+		t.Errorf("expected the table to save work, searched %d nodes then %d", first.Nodes, second.Nodes)
+	}
+	if second.Score != first.Score {
+		t.Errorf("expected the same score got %d and %d", first.Score, second.Score)
+	}
+}
+
+func TestTablebase(t *testing.T) {
+	tb, err := check.GenerateTablebase(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := board(t, check.English, "W:WK1,K2:BK32")
+	_, d, _ := tb.Probe(b)
+	e := New(Limits{Depth: 2})
+	e.Tablebase = tb
+	r, err := e.Search(context.Background(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Score != Win-d {
+		t.Errorf("expected a win in %d plies got score %d", d, r.Score)
+	}
+}
+
+func TestBook(t *testing.T) {
+	bk := check.NewBook(check.English)
+	b := check.English.NewBoard()
+	bk.Add(b, &models.Move{Path: []int32{9, 13}}, 1)
+	e := New(Limits{Depth: 2})
+	e.Book = bk
+	r, err := e.Search(context.Background(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Notation(r.Move) != "9-13" || r.Nodes != 0 {
+		t.Errorf("expected the book move without a search got %s after %d nodes", check.Notation(r.Move), r.Nodes)
+	}
+	g := check.NewGame()
+	g.Apply(r.Move)
+	if r, err = e.Search(context.Background(), g.Board()); err != nil || r.Nodes == 0 {
+		t.Errorf("expected a search out of the book got %v", err)
+	}
+}
+
+// TestMoveKeys checks a king that takes the same pieces going round either
+// way, both moves start and end on 9 and need keys of their own.
+func TestMoveKeys(t *testing.T) {
+	p := newPosition(board(t, check.English, "W:WK9:B6,7,14,15"), nil)
+	n, _ := p.generate()
+	if n != 2 || p.key(0) == p.key(1) {
+		t.Fatalf("expected 2 moves with their own keys got %d", n)
+	}
+	for i := 0; i < n; i++ {
+		if got := find(p, p.key(i)); got != i {
+			t.Errorf("expected move %d got %d", i, got)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// position is a node of the search tree. Moves are referred to by their index
+// in the order the position generates them.
+type position interface {
+	// generate returns the number of legal moves and whether they capture.
+	generate() (int, bool)
+	// child returns the position after move i.
+	child(i int) position
+	move(i int) *models.Move
+	// key identifies move i across positions, see check.MoveKey.
+	key(i int) int32
+	// evaluate scores the position for the player to move.
+	evaluate() int
+	// hash is the position's Zobrist hash, see check.Board.Hash.
+	hash() uint64
+	// probe looks the position up in tb.
+	probe(tb *check.Tablebase) (check.Outcome, int, bool)
+}
+
+// newPosition returns b as a search node evaluated with w, on a bitboard when
+// b allows it.
+func newPosition(b *check.Board, w *check.Weights) position {
+	if bb, ok := b.Bitboard(); ok {
+		return &bitPosition{bb: bb, h: bb.Hash(), w: w}
+	}
+	return &boardPosition{b: b, w: w}
+}
+
+type bitPosition struct {
+	bb    check.Bitboard
+	h     uint64
+	w     *check.Weights
+	moves []check.BitMove
+}
+
+func (p *bitPosition) generate() (int, bool) {
+	if p.moves == nil {
+		p.moves = p.bb.Moves(make([]check.BitMove, 0, 16))
+	}
+	return len(p.moves), len(p.moves) > 0 && p.moves[0].Captures != 0
+}
+
+func (p *bitPosition) child(i int) position {
+	m := p.moves[i]
+	return &bitPosition{bb: p.bb.Play(m), h: p.bb.NextHash(p.h, m), w: p.w}
+}
+
+func (p *bitPosition) move(i int) *models.Move {
+	return p.bb.Move(p.moves[i])
+}
+
+func (p *bitPosition) key(i int) int32 {
+	return p.moves[i].Key()
+}
+
+func (p *bitPosition) hash() uint64 {
+	return p.h
+}
+
+func (p *bitPosition) probe(tb *check.Tablebase) (check.Outcome, int, bool) {
+	return tb.ProbeBitboard(p.bb)
+}
+
+func (p *bitPosition) evaluate() int {
+	return p.w.Evaluate(p.bb.Features())
+}
+
+type boardPosition struct {
+	b     *check.Board
+	w     *check.Weights
+	moves []*models.Move
+}
+
+func (p *boardPosition) generate() (int, bool) {
+	if p.moves == nil {
+		p.moves = p.b.Moves()
+	}
+	return len(p.moves), len(p.moves) > 0 && len(p.moves[0].Captures) > 0
+}
+
+func (p *boardPosition) child(i int) position {
+	b := p.b.Clone()
+	b.Play(p.moves[i])
+	return &boardPosition{b: b, w: p.w}
+}
+
+func (p *boardPosition) move(i int) *models.Move {
+	return p.moves[i]
+}
+
+func (p *boardPosition) hash() uint64 {
+	return p.b.Hash()
+}
+
+func (p *boardPosition) key(i int) int32 {
+	return check.MoveKey(p.moves[i])
+}
+
+func (p *boardPosition) probe(tb *check.Tablebase) (check.Outcome, int, bool) {
+	return tb.Probe(p.b)
+}
+
+func (p *boardPosition) evaluate() int {
+	return p.w.Evaluate(p.b.Features())
+}
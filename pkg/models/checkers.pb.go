@@ -21,8 +21,13 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type Move struct {
-	From                 int32    `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
-	To                   int32    `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	From int32 `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   int32 `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	// Every square the piece stands on during the move, from first to last.
+	// A multiple jump visits more than two squares.
+	Path []int32 `protobuf:"varint,3,rep,packed,name=path,proto3" json:"path,omitempty"`
+	// Squares of the pieces captured by the move, in the order they are jumped.
+	Captures             []int32  `protobuf:"varint,4,rep,packed,name=captures,proto3" json:"captures,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -67,6 +72,20 @@ func (m *Move) GetTo() int32 {
 	return 0
 }
 
+func (m *Move) GetPath() []int32 {
+	if m != nil {
+		return m.Path
+	}
+	return nil
+}
+
+func (m *Move) GetCaptures() []int32 {
+	if m != nil {
+		return m.Captures
+	}
+	return nil
+}
+
 type Piece struct {
 	Id                      int32   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Player                  bool    `protobuf:"varint,2,opt,name=player,proto3" json:"player,omitempty"`
@@ -178,9 +197,11 @@ type Board struct {
 	BlackPieces                       []*Piece         `protobuf:"bytes,12,rep,name=black_pieces,json=blackPieces,proto3" json:"black_pieces,omitempty"`
 	PositionPieces                    map[int32]*Piece `protobuf:"bytes,13,rep,name=position_pieces,json=positionPieces,proto3" json:"position_pieces,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	PieceById                         map[int32]*Piece `protobuf:"bytes,14,rep,name=piece_by_id,json=pieceById,proto3" json:"piece_by_id,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral              struct{}         `json:"-"`
-	XXX_unrecognized                  []byte           `json:"-"`
-	XXX_sizecache                     int32            `json:"-"`
+	// Name of the rules the game is played by, empty for English draughts.
+	Variant              string   `protobuf:"bytes,15,opt,name=variant,proto3" json:"variant,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Board) Reset()         { *m = Board{} }
@@ -306,6 +327,13 @@ func (m *Board) GetPieceById() map[int32]*Piece {
 	return nil
 }
 
+func (m *Board) GetVariant() string {
+	if m != nil {
+		return m.Variant
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Move)(nil), "models.Move")
 	proto.RegisterType((*Piece)(nil), "models.Piece")
@@ -318,43 +346,44 @@ func init() {
 func init() { proto.RegisterFile("checkers.proto", fileDescriptor_49bee1d1b4b6400f) }
 
 var fileDescriptor_49bee1d1b4b6400f = []byte{
-	// 596 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x94, 0x4d, 0x6f, 0xda, 0x4c,
-	0x10, 0xc7, 0x15, 0x13, 0xfb, 0x21, 0x03, 0x18, 0x9e, 0x2d, 0x22, 0x0e, 0xea, 0x21, 0x50, 0xa1,
-	0xa6, 0x3d, 0xa0, 0xa8, 0xbd, 0xb4, 0x51, 0x4f, 0x54, 0x54, 0x7d, 0x51, 0x25, 0x64, 0x55, 0xca,
-	0xd1, 0x32, 0xf6, 0x12, 0x56, 0x18, 0xaf, 0xbb, 0x6b, 0x13, 0xf1, 0x91, 0xfa, 0xd5, 0xfa, 0x29,
-	0xaa, 0x7d, 0x33, 0x26, 0xb8, 0xa7, 0xde, 0xbc, 0xb3, 0xbf, 0xf9, 0xef, 0x7f, 0x76, 0x66, 0x0d,
-	0x6e, 0xb4, 0xc6, 0xd1, 0x06, 0x33, 0x3e, 0xcd, 0x18, 0xcd, 0x29, 0x72, 0xb6, 0x34, 0xc6, 0x09,
-	0x1f, 0xbf, 0x86, 0xf3, 0xef, 0x74, 0x87, 0x11, 0x82, 0xf3, 0x15, 0xa3, 0x5b, 0xef, 0xec, 0xfa,
-	0xec, 0xc6, 0xf6, 0xe5, 0x37, 0x72, 0xc1, 0xca, 0xa9, 0x67, 0xc9, 0x88, 0x95, 0xd3, 0xf1, 0xaf,
-	0x06, 0xd8, 0x0b, 0x82, 0x23, 0x2c, 0x76, 0x48, 0xac, 0x59, 0x8b, 0xc4, 0x68, 0x00, 0x4e, 0x96,
-	0x84, 0x7b, 0xcc, 0x24, 0xdd, 0xf4, 0xf5, 0x0a, 0x0d, 0xa1, 0x99, 0x51, 0x4e, 0x72, 0x42, 0x53,
-	0xaf, 0x21, 0xe9, 0x72, 0x2d, 0x4e, 0xdc, 0x90, 0xf4, 0xc1, 0x3b, 0x97, 0x19, 0xf2, 0x5b, 0xf0,
-	0x51, 0x98, 0xe5, 0x05, 0xc3, 0xb1, 0x67, 0xcb, 0x78, 0xb9, 0x46, 0x33, 0x18, 0x64, 0x94, 0x73,
-	0xb2, 0x4c, 0x70, 0xa0, 0x83, 0xc1, 0x96, 0xee, 0x30, 0xf7, 0x9c, 0xeb, 0xc6, 0x4d, 0xeb, 0x4d,
-	0x7b, 0xaa, 0x4a, 0x9a, 0x8a, 0x7a, 0xfc, 0xbe, 0x61, 0x3f, 0x2a, 0x54, 0x04, 0x39, 0xfa, 0x0c,
-	0x57, 0xa5, 0x86, 0x31, 0x12, 0x26, 0x5a, 0xe6, 0xbf, 0x1a, 0x99, 0x4b, 0x83, 0x2f, 0x4a, 0x5a,
-	0x29, 0xdd, 0x43, 0xbf, 0x6a, 0x22, 0xc0, 0x29, 0xde, 0x12, 0xcc, 0xbd, 0xa6, 0x14, 0x99, 0x18,
-	0x11, 0x79, 0x5d, 0xd3, 0x8a, 0x87, 0xb9, 0xe2, 0xe6, 0x69, 0xce, 0xf6, 0x3e, 0x8a, 0x4e, 0x36,
-	0x86, 0x73, 0xb8, 0xfc, 0x0b, 0x8e, 0x7a, 0xd0, 0xd8, 0xe0, 0xbd, 0xbe, 0x76, 0xf1, 0x89, 0xfa,
-	0x60, 0xef, 0xc2, 0xa4, 0xc0, 0xba, 0x49, 0x6a, 0x71, 0x67, 0xbd, 0x3b, 0x1b, 0xff, 0x76, 0xc0,
-	0x9e, 0xd1, 0x90, 0xc5, 0x95, 0x5e, 0x5d, 0xc8, 0x5e, 0x4d, 0xc0, 0xc9, 0x84, 0x2b, 0xee, 0x59,
-	0xd2, 0x6b, 0xe7, 0xc8, 0xab, 0xaf, 0x37, 0xd1, 0x7b, 0xb8, 0xca, 0x18, 0xde, 0x11, 0x5a, 0x70,
-	0x55, 0xe1, 0x63, 0xc8, 0xcd, 0xbd, 0xcb, 0x5e, 0x36, 0xfd, 0x81, 0x01, 0x84, 0xd3, 0xfb, 0x90,
-	0x6b, 0xdf, 0x68, 0x04, 0x6d, 0xd5, 0xff, 0x3c, 0xc8, 0x0b, 0x96, 0xea, 0x0e, 0xb7, 0x74, 0xec,
-	0x47, 0xc1, 0x52, 0x14, 0xc0, 0x44, 0x9e, 0x13, 0x30, 0xfc, 0xb3, 0x20, 0x8c, 0xa4, 0x0f, 0xc1,
-	0xaa, 0x60, 0xf9, 0x1a, 0xb3, 0x27, 0xbd, 0x15, 0x53, 0x70, 0xe2, 0x71, 0x24, 0x73, 0x7d, 0x93,
-	0xfa, 0x49, 0x65, 0x1e, 0x75, 0xfa, 0x0e, 0xfe, 0x2f, 0x52, 0x33, 0x3b, 0x81, 0x2e, 0xd8, 0xa9,
-	0x2b, 0xb8, 0x77, 0xe0, 0x16, 0xaa, 0xf4, 0x09, 0xb8, 0x34, 0xc3, 0x69, 0x39, 0x21, 0x6a, 0x34,
-	0x6c, 0xbf, 0x23, 0xa2, 0x66, 0x10, 0x38, 0x7a, 0x05, 0xbd, 0x15, 0x49, 0x12, 0x21, 0x5f, 0x82,
-	0x4d, 0x09, 0x76, 0x55, 0xfc, 0x80, 0xbe, 0x84, 0xee, 0xe3, 0x9a, 0xe4, 0xb8, 0x42, 0x5e, 0x48,
-	0xd2, 0x95, 0xe1, 0x23, 0x70, 0x99, 0x84, 0xd1, 0xa6, 0x02, 0x82, 0x02, 0x65, 0xf8, 0x00, 0xde,
-	0x42, 0x5b, 0x2b, 0xaa, 0xd2, 0x5a, 0x75, 0xa5, 0xb5, 0x94, 0xba, 0xaa, 0xea, 0x16, 0xda, 0x5a,
-	0x5a, 0x65, 0xb4, 0x6b, 0x33, 0xd4, 0x31, 0x2a, 0xe3, 0x2b, 0x74, 0x8d, 0x0d, 0x93, 0xd4, 0x91,
-	0x49, 0x23, 0x93, 0x24, 0x27, 0x6c, 0x6a, 0x5c, 0xa9, 0x34, 0x35, 0xda, 0x6e, 0x76, 0x14, 0x44,
-	0x1f, 0xa0, 0xa5, 0x1a, 0xbe, 0xdc, 0x07, 0x24, 0xf6, 0x5c, 0xa9, 0xf3, 0xfc, 0x89, 0x8e, 0x00,
-	0x66, 0xfb, 0x2f, 0xb1, 0x92, 0xb8, 0xc8, 0xcc, 0x7a, 0xb8, 0x80, 0x67, 0x35, 0x87, 0xd4, 0x3c,
-	0x88, 0x17, 0xd5, 0x07, 0x71, 0x52, 0xdd, 0xe1, 0x7d, 0x0c, 0xbf, 0x81, 0x7b, 0x7c, 0xdc, 0x3f,
-	0x88, 0x2d, 0x1d, 0xf9, 0x4f, 0x7d, 0xfb, 0x27, 0x00, 0x00, 0xff, 0xff, 0x51, 0x83, 0xb6, 0xd8,
-	0x65, 0x05, 0x00, 0x00,
+	// 624 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0x4d, 0x6f, 0x13, 0x31,
+	0x10, 0x55, 0xf3, 0xd5, 0x74, 0x92, 0x6e, 0x8a, 0xa9, 0x5a, 0x37, 0xe2, 0xd0, 0x16, 0x45, 0x94,
+	0x4b, 0x54, 0xc1, 0x05, 0x2a, 0x4e, 0x45, 0x45, 0x7c, 0x08, 0x29, 0x5a, 0x21, 0x55, 0xe2, 0xb2,
+	0xda, 0x64, 0xdd, 0xc6, 0xca, 0x66, 0xbd, 0xd8, 0xde, 0x54, 0xb9, 0xf3, 0x67, 0xf8, 0x97, 0xc8,
+	0x63, 0x7b, 0xb3, 0x69, 0x97, 0x13, 0x37, 0xcf, 0xcb, 0x7b, 0xcf, 0x6f, 0x3c, 0xb3, 0x81, 0x60,
+	0x36, 0x67, 0xb3, 0x05, 0x93, 0x6a, 0x9c, 0x4b, 0xa1, 0x05, 0xe9, 0x2c, 0x45, 0xc2, 0x52, 0x75,
+	0xfe, 0x13, 0x5a, 0xdf, 0xc5, 0x8a, 0x11, 0x02, 0xad, 0x3b, 0x29, 0x96, 0x74, 0xe7, 0x74, 0xe7,
+	0xa2, 0x1d, 0xe2, 0x99, 0x04, 0xd0, 0xd0, 0x82, 0x36, 0x10, 0x69, 0x68, 0x61, 0x38, 0x79, 0xac,
+	0xe7, 0xb4, 0x79, 0xda, 0x34, 0x1c, 0x73, 0x26, 0x43, 0xe8, 0xce, 0xe2, 0x5c, 0x17, 0x92, 0x29,
+	0xda, 0x42, 0xbc, 0xac, 0xcf, 0xff, 0x34, 0xa1, 0x3d, 0xe1, 0x6c, 0xc6, 0x8c, 0x13, 0x4f, 0x9c,
+	0x77, 0x83, 0x27, 0xe4, 0x08, 0x3a, 0x79, 0x1a, 0xaf, 0x99, 0x44, 0xf7, 0x6e, 0xe8, 0x2a, 0xe3,
+	0x96, 0x0b, 0xc5, 0x35, 0x17, 0x19, 0x6d, 0x22, 0xbb, 0xac, 0xcd, 0xed, 0x0b, 0x9e, 0xdd, 0xd3,
+	0x16, 0x2a, 0xf0, 0x5c, 0xb9, 0x3d, 0xa1, 0x6d, 0xc4, 0xcb, 0x9a, 0x5c, 0xc3, 0x51, 0x2e, 0x94,
+	0xe2, 0xd3, 0x94, 0x45, 0x0e, 0x8c, 0x96, 0x62, 0xc5, 0x14, 0xed, 0x9c, 0x36, 0x2f, 0x7a, 0x6f,
+	0xfa, 0x63, 0xfb, 0x04, 0x63, 0xd3, 0x7f, 0x78, 0xe8, 0xb9, 0x1f, 0x2d, 0xd5, 0x80, 0x8a, 0x7c,
+	0x86, 0x93, 0xd2, 0xc3, 0x07, 0x89, 0x53, 0x67, 0xb3, 0x5b, 0x63, 0x73, 0xec, 0xe9, 0x93, 0x92,
+	0x6d, 0x9d, 0x6e, 0xe1, 0xb0, 0x1a, 0x22, 0x62, 0x19, 0x5b, 0x72, 0xa6, 0x68, 0x17, 0x4d, 0x46,
+	0xde, 0x04, 0x9f, 0x6b, 0x5c, 0xc9, 0x70, 0x63, 0x79, 0x37, 0x99, 0x96, 0xeb, 0x90, 0xcc, 0x9e,
+	0xfc, 0x30, 0xbc, 0x81, 0xe3, 0x7f, 0xd0, 0xc9, 0x01, 0x34, 0x17, 0x6c, 0xed, 0x9e, 0xdd, 0x1c,
+	0xc9, 0x21, 0xb4, 0x57, 0x71, 0x5a, 0x30, 0x37, 0x54, 0x5b, 0x5c, 0x35, 0xde, 0xed, 0x9c, 0xff,
+	0xde, 0x85, 0xf6, 0xb5, 0x88, 0x65, 0x52, 0x99, 0xd5, 0x1e, 0xce, 0x6a, 0x04, 0x9d, 0xdc, 0xa4,
+	0x52, 0xb4, 0x81, 0x59, 0xf7, 0xb7, 0xb2, 0x86, 0xee, 0x47, 0xf2, 0x1e, 0x4e, 0x72, 0xc9, 0x56,
+	0x5c, 0x14, 0xca, 0x76, 0xf8, 0x10, 0x2b, 0xff, 0xee, 0x38, 0xcb, 0x6e, 0x78, 0xe4, 0x09, 0x26,
+	0xe9, 0x6d, 0xac, 0x5c, 0x6e, 0x72, 0x06, 0x7d, 0x3b, 0x7f, 0x1d, 0xe9, 0x42, 0x66, 0x6e, 0xc2,
+	0x3d, 0x87, 0xfd, 0x28, 0x64, 0x46, 0x22, 0x18, 0xe1, 0x3d, 0x91, 0x64, 0xbf, 0x0a, 0x2e, 0x79,
+	0x76, 0x1f, 0xdd, 0x15, 0x52, 0xcf, 0x99, 0x7c, 0x34, 0x5b, 0xb3, 0x05, 0x4f, 0x32, 0x9e, 0xa1,
+	0x36, 0xf4, 0xd2, 0x4f, 0x56, 0xb9, 0x35, 0xe9, 0x2b, 0x78, 0x56, 0x64, 0x7e, 0x77, 0x22, 0xd7,
+	0x70, 0xa7, 0xae, 0xe1, 0x83, 0x0d, 0x6f, 0x62, 0x5b, 0x1f, 0x41, 0x20, 0x72, 0x96, 0x95, 0x1b,
+	0x62, 0x57, 0xa3, 0x1d, 0xee, 0x1b, 0xd4, 0x2f, 0x82, 0x22, 0xaf, 0xe1, 0xe0, 0x8e, 0xa7, 0xa9,
+	0xb1, 0x2f, 0x89, 0x5d, 0x24, 0x0e, 0x2c, 0xbe, 0xa1, 0xbe, 0x82, 0xc1, 0xc3, 0x9c, 0x6b, 0x56,
+	0x61, 0xee, 0x21, 0x33, 0x40, 0x78, 0x8b, 0x38, 0x4d, 0xe3, 0xd9, 0xa2, 0x42, 0x04, 0x4b, 0x44,
+	0x78, 0x43, 0xbc, 0x84, 0xbe, 0x73, 0xb4, 0xad, 0xf5, 0xea, 0x5a, 0xeb, 0x59, 0x77, 0xdb, 0xd5,
+	0x25, 0xf4, 0x9d, 0xb5, 0x55, 0xf4, 0x6b, 0x15, 0xf6, 0x1a, 0xab, 0xf8, 0x0a, 0x03, 0x1f, 0xc3,
+	0x8b, 0xf6, 0x51, 0x74, 0xe6, 0x45, 0xb8, 0x61, 0x63, 0x9f, 0xca, 0xca, 0xec, 0x6a, 0x07, 0xf9,
+	0x16, 0x48, 0x3e, 0x40, 0xcf, 0x0e, 0x7c, 0xba, 0x8e, 0x78, 0x42, 0x03, 0xf4, 0x79, 0xf1, 0xc8,
+	0xc7, 0x10, 0xae, 0xd7, 0x5f, 0x12, 0x6b, 0xb1, 0x97, 0xfb, 0x9a, 0x50, 0xd8, 0x5d, 0xc5, 0x92,
+	0xc7, 0x99, 0xa6, 0x03, 0x5c, 0x64, 0x5f, 0x0e, 0x27, 0xf0, 0xbc, 0xe6, 0xfa, 0x9a, 0x4f, 0xe5,
+	0x65, 0xf5, 0x53, 0x79, 0xd2, 0xf7, 0xe6, 0xcb, 0x19, 0x7e, 0x83, 0x60, 0x3b, 0xc8, 0x7f, 0x98,
+	0x4d, 0x3b, 0xf8, 0xef, 0xfc, 0xf6, 0xef, 0x00, 0xf3, 0xb3, 0x6d, 0x98, 0xaf, 0x05, 0x00, 0x00,
 }
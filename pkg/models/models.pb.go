@@ -92,24 +92,298 @@ func (m *User) GetUpdatedAt() *timestamp.Timestamp {
 	return nil
 }
 
+// Game is a game between two players, stored with its moves from the
+// starting position.
+type Game struct {
+	Id      string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Variant string `protobuf:"bytes,2,opt,name=variant,proto3" json:"variant,omitempty"`
+	// black and white are the emails of the players.
+	Black string `protobuf:"bytes,3,opt,name=black,proto3" json:"black,omitempty"`
+	White string `protobuf:"bytes,4,opt,name=white,proto3" json:"white,omitempty"`
+	// setup is the FEN of the starting position, empty for the usual one.
+	Setup string  `protobuf:"bytes,5,opt,name=setup,proto3" json:"setup,omitempty"`
+	Moves []*Move `protobuf:"bytes,6,rep,name=moves,proto3" json:"moves,omitempty"`
+	// result and reason are the check.Result and check.Reason of the game.
+	Result    int32                `protobuf:"varint,7,opt,name=result,proto3" json:"result,omitempty"`
+	Reason    int32                `protobuf:"varint,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	CreatedAt *timestamp.Timestamp `protobuf:"bytes,9,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	UpdatedAt *timestamp.Timestamp `protobuf:"bytes,10,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+	// ballot is the opening the game was started with, its moves are the first
+	// of moves.
+	Ballot               *Ballot  `protobuf:"bytes,11,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Game) Reset()         { *m = Game{} }
+func (m *Game) String() string { return proto.CompactTextString(m) }
+func (*Game) ProtoMessage()    {}
+func (*Game) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0b5431a010549573, []int{1}
+}
+
+func (m *Game) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Game.Unmarshal(m, b)
+}
+func (m *Game) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Game.Marshal(b, m, deterministic)
+}
+func (m *Game) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Game.Merge(m, src)
+}
+func (m *Game) XXX_Size() int {
+	return xxx_messageInfo_Game.Size(m)
+}
+func (m *Game) XXX_DiscardUnknown() {
+	xxx_messageInfo_Game.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Game proto.InternalMessageInfo
+
+func (m *Game) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Game) GetVariant() string {
+	if m != nil {
+		return m.Variant
+	}
+	return ""
+}
+
+func (m *Game) GetBlack() string {
+	if m != nil {
+		return m.Black
+	}
+	return ""
+}
+
+func (m *Game) GetWhite() string {
+	if m != nil {
+		return m.White
+	}
+	return ""
+}
+
+func (m *Game) GetSetup() string {
+	if m != nil {
+		return m.Setup
+	}
+	return ""
+}
+
+func (m *Game) GetMoves() []*Move {
+	if m != nil {
+		return m.Moves
+	}
+	return nil
+}
+
+func (m *Game) GetResult() int32 {
+	if m != nil {
+		return m.Result
+	}
+	return 0
+}
+
+func (m *Game) GetReason() int32 {
+	if m != nil {
+		return m.Reason
+	}
+	return 0
+}
+
+func (m *Game) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *Game) GetUpdatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return nil
+}
+
+func (m *Game) GetBallot() *Ballot {
+	if m != nil {
+		return m.Ballot
+	}
+	return nil
+}
+
+// MoveEvent is a move played in a game. The moves of a game are numbered
+// from 1 in the order they were played.
+type MoveEvent struct {
+	Game string `protobuf:"bytes,1,opt,name=game,proto3" json:"game,omitempty"`
+	Seq  int64  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Move *Move  `protobuf:"bytes,3,opt,name=move,proto3" json:"move,omitempty"`
+	// player is the side that played the move, true for black.
+	Player               bool                 `protobuf:"varint,4,opt,name=player,proto3" json:"player,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *MoveEvent) Reset()         { *m = MoveEvent{} }
+func (m *MoveEvent) String() string { return proto.CompactTextString(m) }
+func (*MoveEvent) ProtoMessage()    {}
+func (*MoveEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0b5431a010549573, []int{2}
+}
+
+func (m *MoveEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MoveEvent.Unmarshal(m, b)
+}
+func (m *MoveEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MoveEvent.Marshal(b, m, deterministic)
+}
+func (m *MoveEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MoveEvent.Merge(m, src)
+}
+func (m *MoveEvent) XXX_Size() int {
+	return xxx_messageInfo_MoveEvent.Size(m)
+}
+func (m *MoveEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_MoveEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MoveEvent proto.InternalMessageInfo
+
+func (m *MoveEvent) GetGame() string {
+	if m != nil {
+		return m.Game
+	}
+	return ""
+}
+
+func (m *MoveEvent) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *MoveEvent) GetMove() *Move {
+	if m != nil {
+		return m.Move
+	}
+	return nil
+}
+
+func (m *MoveEvent) GetPlayer() bool {
+	if m != nil {
+		return m.Player
+	}
+	return false
+}
+
+func (m *MoveEvent) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+// Ballot is an opening dealt by lot, see check.Ballot.
+type Ballot struct {
+	// number identifies the ballot in its list, starting at 1.
+	Number               int32    `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Moves                []*Move  `protobuf:"bytes,3,rep,name=moves,proto3" json:"moves,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Ballot) Reset()         { *m = Ballot{} }
+func (m *Ballot) String() string { return proto.CompactTextString(m) }
+func (*Ballot) ProtoMessage()    {}
+func (*Ballot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0b5431a010549573, []int{3}
+}
+
+func (m *Ballot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Ballot.Unmarshal(m, b)
+}
+func (m *Ballot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Ballot.Marshal(b, m, deterministic)
+}
+func (m *Ballot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Ballot.Merge(m, src)
+}
+func (m *Ballot) XXX_Size() int {
+	return xxx_messageInfo_Ballot.Size(m)
+}
+func (m *Ballot) XXX_DiscardUnknown() {
+	xxx_messageInfo_Ballot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Ballot proto.InternalMessageInfo
+
+func (m *Ballot) GetNumber() int32 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *Ballot) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Ballot) GetMoves() []*Move {
+	if m != nil {
+		return m.Moves
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*User)(nil), "models.User")
+	proto.RegisterType((*Game)(nil), "models.Game")
+	proto.RegisterType((*MoveEvent)(nil), "models.MoveEvent")
+	proto.RegisterType((*Ballot)(nil), "models.Ballot")
 }
 
 func init() { proto.RegisterFile("models.proto", fileDescriptor_0b5431a010549573) }
 
 var fileDescriptor_0b5431a010549573 = []byte{
-	// 177 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xc9, 0xcd, 0x4f, 0x49,
-	0xcd, 0x29, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x83, 0xf0, 0xa4, 0xe4, 0xd3, 0xf3,
-	0xf3, 0xd3, 0x73, 0x52, 0xf5, 0xc1, 0xa2, 0x49, 0xa5, 0x69, 0xfa, 0x25, 0x99, 0xb9, 0xa9, 0xc5,
-	0x25, 0x89, 0xb9, 0x05, 0x10, 0x85, 0x4a, 0xfb, 0x18, 0xb9, 0x58, 0x42, 0x8b, 0x53, 0x8b, 0x84,
-	0x84, 0xb8, 0x58, 0xf2, 0x12, 0x73, 0x53, 0x25, 0x18, 0x15, 0x18, 0x35, 0x38, 0x83, 0xc0, 0x6c,
-	0x21, 0x11, 0x2e, 0xd6, 0xd4, 0xdc, 0xc4, 0xcc, 0x1c, 0x09, 0x26, 0xb0, 0x20, 0x84, 0x23, 0x24,
-	0xc1, 0xc5, 0x5e, 0x90, 0x99, 0x5c, 0x52, 0x5a, 0x94, 0x2a, 0xc1, 0x0c, 0x16, 0x87, 0x71, 0x85,
-	0x2c, 0xb8, 0x38, 0x93, 0x8b, 0x52, 0x13, 0x4b, 0x52, 0x53, 0x1c, 0x4b, 0x24, 0x58, 0x14, 0x18,
-	0x35, 0xb8, 0x8d, 0xa4, 0xf4, 0x20, 0x2e, 0xd0, 0x83, 0xb9, 0x40, 0x2f, 0x04, 0xe6, 0x82, 0x20,
-	0x84, 0x62, 0x90, 0xce, 0xd2, 0x82, 0x14, 0xa8, 0x4e, 0x56, 0xc2, 0x3a, 0xe1, 0x8a, 0x93, 0xd8,
-	0xc0, 0xd2, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x7c, 0x1d, 0xfc, 0x78, 0x00, 0x01, 0x00,
-	0x00,
+	// 407 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0xcf, 0x8a, 0xd5, 0x30,
+	0x14, 0xc6, 0xe9, 0xdf, 0x99, 0x9e, 0x3b, 0x5c, 0x24, 0x88, 0x84, 0xbb, 0xb1, 0x74, 0x21, 0x5d,
+	0x75, 0x60, 0xdc, 0xb8, 0x55, 0x10, 0x57, 0x6e, 0x82, 0x82, 0xdb, 0xb4, 0x3d, 0xde, 0x29, 0x93,
+	0x36, 0x35, 0x49, 0x2b, 0x3e, 0x90, 0xaf, 0xe1, 0xc3, 0xf8, 0x24, 0x92, 0x3f, 0xbd, 0xce, 0x15,
+	0x44, 0xae, 0xbb, 0x7c, 0x5f, 0x72, 0xda, 0xdf, 0xf9, 0xce, 0x81, 0x9b, 0x51, 0xf6, 0x28, 0x74,
+	0x33, 0x2b, 0x69, 0x24, 0xc9, 0xbd, 0x3a, 0x3c, 0x3f, 0x4a, 0x79, 0x14, 0x78, 0xeb, 0xdc, 0x76,
+	0xf9, 0x7c, 0x6b, 0x86, 0x11, 0xb5, 0xe1, 0xe3, 0xec, 0x1f, 0x1e, 0xf6, 0xdd, 0x3d, 0x76, 0x0f,
+	0xa8, 0x42, 0x61, 0xf5, 0x23, 0x82, 0xf4, 0xa3, 0x46, 0x45, 0x08, 0xa4, 0x13, 0x1f, 0x91, 0x46,
+	0x65, 0x54, 0x17, 0xcc, 0x9d, 0xc9, 0x53, 0xc8, 0x70, 0xe4, 0x83, 0xa0, 0xb1, 0x33, 0xbd, 0x20,
+	0x14, 0xae, 0xe6, 0xa1, 0x33, 0x8b, 0x42, 0x9a, 0x38, 0x7f, 0x93, 0xe4, 0x15, 0x14, 0x9d, 0x42,
+	0x6e, 0xb0, 0x7f, 0x6d, 0x68, 0x5a, 0x46, 0xf5, 0xee, 0xee, 0xd0, 0x78, 0xa2, 0x66, 0x23, 0x6a,
+	0x3e, 0x6c, 0x44, 0xec, 0xf7, 0x63, 0x5b, 0xb9, 0xcc, 0x7d, 0xa8, 0xcc, 0xfe, 0x5d, 0x79, 0x7a,
+	0x5c, 0xfd, 0x8c, 0x21, 0x7d, 0x67, 0x61, 0xf7, 0x10, 0x0f, 0x7d, 0xc0, 0x8f, 0x87, 0xde, 0x62,
+	0xae, 0x5c, 0x0d, 0x7c, 0x32, 0x01, 0x7f, 0x93, 0xb6, 0xad, 0x56, 0xf0, 0xee, 0x21, 0xe0, 0x7b,
+	0x61, 0xdd, 0xaf, 0xf7, 0x83, 0x41, 0x07, 0x5e, 0x30, 0x2f, 0xac, 0xab, 0xd1, 0x2c, 0xb3, 0x83,
+	0x2a, 0x98, 0x17, 0xa4, 0x82, 0x6c, 0x94, 0x2b, 0x6a, 0x9a, 0x97, 0x49, 0xbd, 0xbb, 0xbb, 0x69,
+	0xc2, 0x30, 0xde, 0xcb, 0x15, 0x99, 0xbf, 0x22, 0xcf, 0x20, 0x57, 0xa8, 0x17, 0x61, 0xe8, 0x55,
+	0x19, 0xd5, 0x19, 0x0b, 0xca, 0xfb, 0x5c, 0xcb, 0x89, 0x5e, 0x6f, 0xbe, 0x55, 0xe7, 0xe1, 0x15,
+	0xff, 0x1d, 0x1e, 0x5c, 0x10, 0x1e, 0x79, 0x01, 0x79, 0xcb, 0x85, 0x90, 0x86, 0xee, 0x5c, 0xd9,
+	0x7e, 0x6b, 0xe4, 0x8d, 0x73, 0x59, 0xb8, 0xad, 0xbe, 0x47, 0x50, 0xd8, 0xde, 0xde, 0xae, 0x38,
+	0x19, 0xbb, 0x2a, 0xc7, 0x47, 0xab, 0x62, 0xcf, 0xe4, 0x09, 0x24, 0x1a, 0xbf, 0xb8, 0xa4, 0x13,
+	0x66, 0x8f, 0xa4, 0x84, 0xd4, 0x06, 0xe1, 0x42, 0xfe, 0x33, 0x22, 0x77, 0x63, 0x93, 0x98, 0x05,
+	0xff, 0x86, 0xca, 0x45, 0x7e, 0xcd, 0x82, 0x3a, 0x4f, 0x22, 0xbb, 0x20, 0x89, 0xea, 0x13, 0xe4,
+	0x9e, 0xdc, 0x7e, 0x7b, 0x5a, 0xc6, 0x16, 0x95, 0xa3, 0xcc, 0x58, 0x50, 0xa7, 0x35, 0x8f, 0x1f,
+	0xad, 0xf9, 0x69, 0x9a, 0xc9, 0x5f, 0xa7, 0xd9, 0xe6, 0xee, 0xc7, 0x2f, 0x7f, 0x0d, 0x00, 0x91,
+	0xa6, 0x7b, 0x7d, 0x77, 0x03, 0x00, 0x00,
 }
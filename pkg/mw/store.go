@@ -0,0 +1,17 @@
+package mw
+
+import (
+	"net/http"
+
+	"github.com/gernest/8x8/pkg/storage"
+	"github.com/justinas/alice"
+)
+
+// Store makes s available to handlers through storage.Get.
+func Store(s storage.Store) alice.Constructor {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(storage.Set(r.Context(), s)))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package pdn
+
+import (
+	"fmt"
+
+	"github.com/gernest/8x8/pkg/check"
+)
+
+// Book compiles an opening book from the first plies moves of games, which
+// must all be of the same variant. A move is weighted by how its game went for
+// the player who made it: 2 for a win, 1 for a draw or an unfinished game and
+// 0 for a loss, so moves that only ever lost are known but never chosen.
+func Book(games []*Game, plies int) (*check.Book, error) {
+	var bk *check.Book
+	for i, g := range games {
+		v, err := g.Variant()
+		if err != nil {
+			return nil, fmt.Errorf("pdn: game %d: %w", i+1, err)
+		}
+		switch {
+		case bk == nil:
+			bk = check.NewBook(v)
+		case bk.Variant != v:
+			return nil, fmt.Errorf("pdn: game %d: %w: %s in a %s book", i+1, ErrGameType, v.Name, bk.Variant.Name)
+		}
+		game, err := g.start(v)
+		if err != nil {
+			return nil, fmt.Errorf("pdn: game %d: %w", i+1, err)
+		}
+		for j, m := range g.Moves {
+			if j >= plies || game.Over() {
+				break
+			}
+			b := game.Board().Clone()
+			p := game.Turn()
+			if err := game.Apply(m.Move); err != nil {
+				return nil, fmt.Errorf("pdn: game %d: move %d %s: %w", i+1, j+1, check.Notation(m.Move), err)
+			}
+			history := game.History()
+			bk.Add(b, history[len(history)-1], weight(g.Result, p))
+		}
+	}
+	if bk == nil {
+		bk = check.NewBook(check.English)
+	}
+	return bk, nil
+}
+
+func weight(r check.Result, p check.Player) int {
+	switch {
+	case r == check.Ongoing || r == check.Draw:
+		return 1
+	case (r == check.WhiteWins) == (p == check.White):
+		return 2
+	default:
+		return 0
+	}
+}
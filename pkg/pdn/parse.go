@@ -0,0 +1,311 @@
+package pdn
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// Parse reads every game in r. Variations are skipped, only the main line of
+// a game is kept.
+func Parse(r io.Reader) ([]*Game, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{s: string(data), line: 1}
+	return p.games()
+}
+
+// ParseString reads every game in s.
+func ParseString(s string) ([]*Game, error) {
+	return Parse(strings.NewReader(s))
+}
+
+type parser struct {
+	s    string
+	pos  int
+	line int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: line %d: %s", ErrSyntax, p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *parser) next() byte {
+	c := p.s[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() {
+		switch c := p.s[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.next()
+		case c == '%' && (p.pos == 0 || p.s[p.pos-1] == '\n'), c == ';':
+			// Escaped lines and rest of line comments.
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *parser) games() ([]*Game, error) {
+	var games []*Game
+	var g *Game
+	finish := func() {
+		if g.Result == 0 {
+			g.Result, _ = parseResult(g.Tag("Result"))
+		}
+		games = append(games, g)
+		g = nil
+	}
+	for p.skipSpace(); !p.eof(); p.skipSpace() {
+		if g == nil {
+			g = &Game{}
+		}
+		switch p.s[p.pos] {
+		case '[':
+			if len(g.Moves) > 0 {
+				// A game without a result token ends where the next begins.
+				finish()
+				g = &Game{}
+			}
+			tag, err := p.tag()
+			if err != nil {
+				return nil, err
+			}
+			g.Tags = append(g.Tags, tag)
+		case '{':
+			text, err := p.comment()
+			if err != nil {
+				return nil, err
+			}
+			if len(g.Moves) == 0 {
+				g.Comment = join(g.Comment, text)
+			} else {
+				last := &g.Moves[len(g.Moves)-1]
+				last.Comment = join(last.Comment, text)
+			}
+		case '(':
+			if err := p.variation(); err != nil {
+				return nil, err
+			}
+		case '$', '!', '?':
+			nag, err := p.nag()
+			if err != nil {
+				return nil, err
+			}
+			if len(g.Moves) == 0 {
+				return nil, p.errorf("annotation before the first move")
+			}
+			last := &g.Moves[len(g.Moves)-1]
+			last.NAGs = append(last.NAGs, nag)
+		default:
+			word := p.word()
+			if r, ok := parseResult(word); ok {
+				g.Result = r
+				finish()
+				continue
+			}
+			// Move numbers, 1. or 1... before a move by the second player.
+			if i := strings.LastIndexByte(word, '.'); i >= 0 {
+				if _, err := strconv.Atoi(strings.TrimRight(word[:i], ".")); err != nil {
+					return nil, p.errorf("bad move number %q", word)
+				}
+				word = word[i+1:]
+				if word == "" {
+					continue
+				}
+			}
+			m, err := parseMove(word)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			g.Moves = append(g.Moves, Move{Move: m})
+		}
+	}
+	if g != nil {
+		finish()
+	}
+	return games, nil
+}
+
+func join(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
+
+// tag reads a tag pair.
+func (p *parser) tag() (Tag, error) {
+	p.next()
+	p.skipSpace()
+	start := p.pos
+	for !p.eof() && isNameByte(p.s[p.pos]) {
+		p.next()
+	}
+	t := Tag{Name: p.s[start:p.pos]}
+	if t.Name == "" {
+		return t, p.errorf("tag without a name")
+	}
+	p.skipSpace()
+	if p.eof() || p.next() != '"' {
+		return t, p.errorf("tag %s without a value", t.Name)
+	}
+	var value strings.Builder
+	for {
+		if p.eof() {
+			return t, p.errorf("unterminated value of tag %s", t.Name)
+		}
+		c := p.next()
+		if c == '"' {
+			break
+		}
+		if c == '\\' && !p.eof() {
+			c = p.next()
+		}
+		value.WriteByte(c)
+	}
+	t.Value = value.String()
+	p.skipSpace()
+	if p.eof() || p.next() != ']' {
+		return t, p.errorf("unterminated tag %s", t.Name)
+	}
+	return t, nil
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// comment reads a comment in braces.
+func (p *parser) comment() (string, error) {
+	line := p.line
+	p.next()
+	start := p.pos
+	for !p.eof() {
+		if p.next() == '}' {
+			return strings.TrimSpace(p.s[start : p.pos-1]), nil
+		}
+	}
+	p.line = line
+	return "", p.errorf("unterminated comment")
+}
+
+// variation skips a variation, which may hold comments and variations of
+// its own.
+func (p *parser) variation() error {
+	line := p.line
+	depth := 0
+	for !p.eof() {
+		switch p.s[p.pos] {
+		case '{':
+			if _, err := p.comment(); err != nil {
+				return err
+			}
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		p.next()
+		if depth == 0 {
+			return nil
+		}
+	}
+	p.line = line
+	return p.errorf("unterminated variation")
+}
+
+var strength = map[string]int{
+	"!":  1,
+	"?":  2,
+	"!!": 3,
+	"??": 4,
+	"!?": 5,
+	"?!": 6,
+}
+
+// nag reads a numeric annotation glyph or a move strength suffix.
+func (p *parser) nag() (int, error) {
+	start := p.pos
+	if p.next() == '$' {
+		for !p.eof() && '0' <= p.s[p.pos] && p.s[p.pos] <= '9' {
+			p.next()
+		}
+		n, err := strconv.Atoi(p.s[start+1 : p.pos])
+		if err != nil {
+			return 0, p.errorf("bad annotation %q", p.s[start:p.pos])
+		}
+		return n, nil
+	}
+	for !p.eof() && (p.s[p.pos] == '!' || p.s[p.pos] == '?') {
+		p.next()
+	}
+	n, ok := strength[p.s[start:p.pos]]
+	if !ok {
+		return 0, p.errorf("bad annotation %q", p.s[start:p.pos])
+	}
+	return n, nil
+}
+
+// word reads up to the next space or token of its own.
+func (p *parser) word() string {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n[]{}()$!?;", rune(p.s[p.pos])) {
+		p.next()
+	}
+	if p.pos == start {
+		// A stray closing bracket.
+		p.next()
+	}
+	return p.s[start:p.pos]
+}
+
+// parseMove reads a move in numeric notation. A capture may list every
+// square it passes or only where it starts and ends.
+func parseMove(s string) (*models.Move, error) {
+	// A trailing * marks a forced move.
+	s = strings.TrimSuffix(s, "*")
+	sep := "-"
+	if strings.Contains(s, "x") {
+		sep = "x"
+	}
+	parts := strings.Split(s, sep)
+	if len(parts) < 2 || sep == "-" && len(parts) > 2 {
+		return nil, fmt.Errorf("bad move %q", s)
+	}
+	path := make([]int32, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("bad move %q", s)
+		}
+		path[i] = int32(n)
+	}
+	m := &models.Move{From: path[0], To: path[len(path)-1]}
+	if len(path) > 2 {
+		m.Path = path
+	}
+	return m, nil
+}
@@ -0,0 +1,207 @@
+// Package pdn reads and writes games in Portable Draughts Notation 3.0.
+package pdn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+)
+
+var (
+	// ErrSyntax is wrapped by errors about malformed PDN.
+	ErrSyntax = errors.New("pdn: syntax error")
+
+	// ErrGameType is returned when a game is played by rules that are not
+	// supported.
+	ErrGameType = errors.New("pdn: unsupported game type")
+
+	// ErrResult is returned when the result of a game does not match the
+	// position its moves lead to.
+	ErrResult = errors.New("pdn: result does not match the moves")
+)
+
+// Tag is a tag pair such as [Event "Casual game"].
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Move is a move of a game record with its annotations.
+type Move struct {
+	*models.Move
+	// NAGs are the numeric annotation glyphs of the move. Move strength
+	// suffixes are read as their glyphs, "!" is 1, "?" is 2 and so on.
+	NAGs []int
+	// Comment follows the move.
+	Comment string
+}
+
+// Game is a game record.
+type Game struct {
+	Tags []Tag
+	// Comment comes before the first move.
+	Comment string
+	Moves   []Move
+	Result  check.Result
+}
+
+// Tag returns the value of the tag called name, or an empty string.
+func (g *Game) Tag(name string) string {
+	for _, t := range g.Tags {
+		if t.Name == name {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// SetTag sets the tag called name to value, adding it when there is none.
+func (g *Game) SetTag(name, value string) {
+	for i, t := range g.Tags {
+		if t.Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, Tag{Name: name, Value: value})
+}
+
+// Variant returns the rules named by the GameType tag. A game without one is
+// English draughts.
+func (g *Game) Variant() (*check.Variant, error) {
+	tag := g.Tag("GameType")
+	if tag == "" {
+		return check.English, nil
+	}
+	// The full form adds the board layout after a comma, the number is enough
+	// to tell the rules apart.
+	n, err := strconv.Atoi(strings.TrimSpace(strings.SplitN(tag, ",", 2)[0]))
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrGameType, tag)
+	}
+	for _, v := range check.Variants {
+		if v.GameType == n {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %d", ErrGameType, n)
+}
+
+// New returns the record of moves played by the rules of v from the start.
+// The moves are validated and completed with their paths and captures.
+func New(v *check.Variant, moves []*models.Move, tags ...Tag) (*Game, error) {
+	game := v.NewGame()
+	for i, m := range moves {
+		if err := game.Apply(m); err != nil {
+			return nil, fmt.Errorf("pdn: move %d: %w", i+1, err)
+		}
+	}
+	return Record(game, tags...), nil
+}
+
+// Record returns the record of the moves played in game. The GameType and
+// Result tags are set from the game, the FEN tag when it did not start from
+// the usual position and the Ballot and Opening tags when it started with a
+// ballot.
+func Record(game *check.Game, tags ...Tag) *Game {
+	g := &Game{
+		Tags:   append([]Tag(nil), tags...),
+		Result: game.Result(),
+	}
+	for _, m := range game.History() {
+		g.Moves = append(g.Moves, Move{Move: m})
+	}
+	g.SetTag("Result", resultText(g.Result))
+	g.SetTag("GameType", strconv.Itoa(game.Variant().GameType))
+	if fen := game.Setup(); fen != "" {
+		g.SetTag("FEN", fen)
+	}
+	if b, ok := game.Ballot(); ok {
+		g.SetTag("Ballot", strconv.Itoa(b.Number))
+		if b.Name != "" {
+			g.SetTag("Opening", b.Name)
+		}
+	}
+	return g
+}
+
+// Replay plays the moves of g from the start, or from the position of the FEN
+// tag, and returns the game they make. Every move is checked to be legal, a
+// result the moves do not reach is taken as a resignation or an agreed draw.
+func (g *Game) Replay() (*check.Game, error) {
+	v, err := g.Variant()
+	if err != nil {
+		return nil, err
+	}
+	game, err := g.start(v)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range g.Moves {
+		if game.Over() {
+			return nil, fmt.Errorf("pdn: move %d %s: %w", i+1, check.Notation(m.Move), check.ErrGameOver)
+		}
+		if err := game.Apply(m.Move); err != nil {
+			return nil, fmt.Errorf("pdn: move %d %s: %w", i+1, check.Notation(m.Move), err)
+		}
+	}
+	switch {
+	case g.Result == check.Ongoing || g.Result == game.Result():
+	case game.Over():
+		return nil, fmt.Errorf("%w: %s after the moves, %s in the record", ErrResult, game.Result(), g.Result)
+	case g.Result == check.Draw:
+		game.AgreeDraw()
+	case g.Result == check.WhiteWins:
+		game.Resign(check.Black)
+	case g.Result == check.BlackWins:
+		game.Resign(check.White)
+	}
+	return game, nil
+}
+
+// start returns the game before the first move, set up by the FEN tag when
+// there is one.
+func (g *Game) start(v *check.Variant) (*check.Game, error) {
+	fen := g.Tag("FEN")
+	if fen == "" {
+		return v.NewGame(), nil
+	}
+	b, err := v.ParseFEN(fen)
+	if err != nil {
+		return nil, fmt.Errorf("pdn: %w", err)
+	}
+	return check.NewGameFrom(b), nil
+}
+
+// resultText returns r as it is written in PDN.
+func resultText(r check.Result) string {
+	switch r {
+	case check.WhiteWins:
+		return "2-0"
+	case check.BlackWins:
+		return "0-2"
+	case check.Draw:
+		return "1-1"
+	default:
+		return "*"
+	}
+}
+
+// parseResult reads a result token, including the older chess style ones.
+func parseResult(s string) (check.Result, bool) {
+	switch s {
+	case "2-0", "1-0":
+		return check.WhiteWins, true
+	case "0-2", "0-1":
+		return check.BlackWins, true
+	case "1-1", "1/2-1/2":
+		return check.Draw, true
+	case "*":
+		return check.Ongoing, true
+	}
+	return check.Ongoing, false
+}
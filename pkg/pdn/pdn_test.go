@@ -0,0 +1,233 @@
+package pdn
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+)
+
+const sample = `% exported by hand
+[Event "Club championship"]
+[White "Ann \"the wall\""]
+[Black "Bob"]
+[Result "2-0"]
+[GameType "21"]
+
+{Old fashioned opening} 1. 11-15 22-18 2. 15x22 {forced} 25x18! 3. 8-11
+(3. 9-14 18x9 {a variation} (3... 5x14) 5x14) 29-25 $14 4. 4-8 25-22 2-0
+`
+
+func TestParse(t *testing.T) {
+	games, err := ParseString(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 {
+		t.Fatalf("expected 1 game got %d", len(games))
+	}
+	g := games[0]
+	if v := g.Tag("White"); v != `Ann "the wall"` {
+		t.Errorf("expected escaped quotes in White got %q", v)
+	}
+	if g.Result != check.WhiteWins {
+		t.Errorf("expected white to win got %v", g.Result)
+	}
+	if g.Comment != "Old fashioned opening" {
+		t.Errorf("expected the game comment got %q", g.Comment)
+	}
+	if len(g.Moves) != 8 {
+		t.Fatalf("expected 8 moves got %d", len(g.Moves))
+	}
+	if c := g.Moves[2].Comment; c != "forced" {
+		t.Errorf("expected a comment on 15x22 got %q", c)
+	}
+	if n := g.Moves[3].NAGs; !reflect.DeepEqual(n, []int{1}) {
+		t.Errorf("expected ! on 25x18 got %v", n)
+	}
+	if n := g.Moves[5].NAGs; !reflect.DeepEqual(n, []int{14}) {
+		t.Errorf("expected $14 on 29-25 got %v", n)
+	}
+
+	game, err := g.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Result() != check.WhiteWins || game.Reason() != check.Resignation {
+		t.Errorf("expected black to have resigned got %v by %v", game.Result(), game.Reason())
+	}
+	if n := len(game.History()); n != 8 {
+		t.Errorf("expected 8 moves played got %d", n)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	games, err := ParseString(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := games[0].String()
+	again, err := ParseString(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(games, again) {
+		t.Errorf("expected the same game after writing\n%s", text)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	v := check.International
+	game := v.NewGame()
+	// Play the first legal move until the game has some captures in it.
+	for i := 0; i < 40 && !game.Over(); i++ {
+		if err := game.Apply(game.LegalMoves()[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g := Record(game, Tag{Name: "Event", Value: "Test"})
+	if g.Tag("GameType") != "20" {
+		t.Errorf("expected GameType 20 got %q", g.Tag("GameType"))
+	}
+	var b strings.Builder
+	if err := Write(&b, g, g); err != nil {
+		t.Fatal(err)
+	}
+	games, err := ParseString(b.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("expected 2 games got %d", len(games))
+	}
+	replay, err := games[1].Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replay.History(), game.History()) {
+		t.Error("expected the replayed game to have the same moves")
+	}
+}
+
+func TestRecordBallot(t *testing.T) {
+	ballots, err := check.ReadBallots(check.English, strings.NewReader("9-13 21-17 5-9\n11-15 23-19 8-11 Test opening\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, err := check.English.NewBallotGame(ballots[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := Record(game)
+	if g.Tag("Ballot") != "2" || g.Tag("Opening") != "Test opening" || len(g.Moves) != 3 {
+		t.Errorf("expected the ballot in the record got %v with %d moves", g.Tags, len(g.Moves))
+	}
+}
+
+func TestNew(t *testing.T) {
+	g, err := New(check.English, []*models.Move{{From: 11, To: 15}, {From: 22, To: 18}, {From: 15, To: 22}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := g.Moves[2].Captures; !reflect.DeepEqual(c, []int32{18}) {
+		t.Errorf("expected the capture of 18 got %v", c)
+	}
+	_, err = New(check.English, []*models.Move{{From: 11, To: 15}, {From: 11, To: 15}})
+	if !errors.Is(err, check.ErrIllegalMove) {
+		t.Errorf("expected an illegal move got %v", err)
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		pdn  string
+		err  error
+	}{
+		{"illegal move", "1. 11-15 22-17 2. 15-22 *", check.ErrIllegalMove},
+		{"game type", "[GameType \"99\"]\n1. 11-15 *", ErrGameType},
+		{"setup", "[FEN \"W:W21:B1,1\"]\n1. 21-17 *", check.ErrFEN},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			games, err := ParseString(c.pdn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := games[0].Replay(); !errors.Is(err, c.err) {
+				t.Errorf("expected %v got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	// White to move first in English draughts, where Black normally starts.
+	text := "[FEN \"W:WK14,21:B9,10\"]\n1... 14x5 *"
+	games, err := ParseString(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, err := games[0].Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Setup() != "W:WK14,21:B9,10" {
+		t.Errorf("expected the game to keep its setup got %q", game.Setup())
+	}
+	g := Record(game)
+	if fen := g.Tag("FEN"); fen != game.Setup() {
+		t.Errorf("expected the FEN tag to be set got %q", fen)
+	}
+	if s := g.String(); !strings.Contains(s, "1... 14x5 *") {
+		t.Errorf("expected the move text to start with 1...\n%s", s)
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	for _, s := range []string{
+		`[Event "unterminated]`,
+		`1. 11-15 {no end`,
+		`1. 11-15 (22-18`,
+		`1. eleven-15`,
+		`$1 11-15`,
+	} {
+		if _, err := ParseString(s); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: expected a syntax error got %v", s, err)
+		}
+	}
+}
+
+func TestBook(t *testing.T) {
+	games, err := ParseString(`
+1. 11-15 23-19 2. 8-11 2-0
+1. 11-15 22-17 0-2
+1. 9-14 *
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bk, err := Book(games, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The start and the position after 11-15.
+	if bk.Len() != 2 {
+		t.Errorf("expected 2 positions got %d", bk.Len())
+	}
+	var got []string
+	for _, m := range bk.Moves(check.English.NewBoard()) {
+		got = append(got, fmt.Sprintf("%s %d", check.Notation(m.Move), m.Weight))
+	}
+	if want := []string{"11-15 2", "9-14 1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+	games = append(games, &Game{Tags: []Tag{{Name: "GameType", Value: "20"}}})
+	if _, err := Book(games, 2); !errors.Is(err, ErrGameType) {
+		t.Errorf("expected mixed variants to fail got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package pdn
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gernest/8x8/pkg/check"
+)
+
+// lineWidth is the length move text lines are wrapped at.
+const lineWidth = 79
+
+// Write writes games to w separated by blank lines.
+func Write(w io.Writer, games ...*Game) error {
+	bw := bufio.NewWriter(w)
+	for i, g := range games {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		g.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (g *Game) String() string {
+	var s strings.Builder
+	g.write(&s)
+	return s.String()
+}
+
+type stringWriter interface {
+	WriteString(string) (int, error)
+}
+
+func (g *Game) write(w stringWriter) {
+	for _, t := range g.Tags {
+		value := t.Value
+		if t.Name == "Result" {
+			value = resultText(g.Result)
+		}
+		w.WriteString("[" + t.Name + " " + quote(value) + "]\n")
+	}
+	if len(g.Tags) > 0 {
+		w.WriteString("\n")
+	}
+	var tokens []string
+	if g.Comment != "" {
+		tokens = append(tokens, comment(g.Comment))
+	}
+	// A game set up with the second player to move starts with 1...
+	first := 0
+	if g.secondMoves() {
+		first = 1
+		tokens = append(tokens, "1...")
+	}
+	for i, m := range g.Moves {
+		if ply := i + first; ply%2 == 0 {
+			tokens = append(tokens, strconv.Itoa(ply/2+1)+".")
+		}
+		tokens = append(tokens, check.Notation(m.Move))
+		for _, n := range m.NAGs {
+			tokens = append(tokens, "$"+strconv.Itoa(n))
+		}
+		if m.Comment != "" {
+			tokens = append(tokens, comment(m.Comment))
+		}
+	}
+	tokens = append(tokens, resultText(g.Result))
+	line := 0
+	for i, t := range tokens {
+		switch {
+		case i == 0:
+		case line+1+len(t) > lineWidth:
+			w.WriteString("\n")
+			line = 0
+		default:
+			w.WriteString(" ")
+			line++
+		}
+		w.WriteString(t)
+		line += len(t)
+	}
+	w.WriteString("\n")
+}
+
+// secondMoves reports whether the FEN tag gives the move to the player who
+// does not move first in a normal game.
+func (g *Game) secondMoves() bool {
+	v, err := g.Variant()
+	if err != nil || g.Tag("FEN") == "" {
+		return false
+	}
+	b, err := v.ParseFEN(g.Tag("FEN"))
+	return err == nil && check.Player(b.PlayertTurn) != v.First
+}
+
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// comment returns s as a comment. A comment can not contain its closing
+// brace, so those are dropped.
+func comment(s string) string {
+	return "{" + strings.ReplaceAll(s, "}", "") + "}"
+}
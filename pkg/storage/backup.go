@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/gernest/8x8/pkg/models"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// ErrNotEmpty is returned when restoring a backup over a database that has
+// data.
+var ErrNotEmpty = errors.New("storage: database is not empty")
+
+// Backup writes a full backup of the database to w. It reads a snapshot of
+// the database, so the store can be used while it runs.
+func (d *DefaultStore) Backup(w io.Writer) error {
+	_, err := d.DB.Backup(w, 0)
+	return err
+}
+
+// Restore loads a backup written by Backup. The database must be empty, a
+// backup is not merged with data that may be newer.
+func (d *DefaultStore) Restore(r io.Reader) error {
+	empty := true
+	err := d.DB.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		it.Rewind()
+		empty = !it.Valid()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return ErrNotEmpty
+	}
+	return d.DB.Load(r, 256)
+}
+
+// Export writes the users and games of s to w as JSON, one object per line
+// with the record under its kind, {"user": {...}} or {"game": {...}}. Games
+// come with their moves.
+func Export(ctx context.Context, s Store, w io.Writer) error {
+	m := &jsonpb.Marshaler{}
+	line := func(kind string, msg proto.Message) error {
+		data, err := m.MarshalToString(msg)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "{%q:%s}\n", kind, data)
+		return err
+	}
+	users, err := s.User().List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, usr := range users {
+		if err := line("user", usr); err != nil {
+			return err
+		}
+	}
+	return s.Games().Walk(ctx, func(g *models.Game) error {
+		return line("game", g)
+	})
+}
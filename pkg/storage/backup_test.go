@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+func TestBackup(t *testing.T) {
+	ctx := context.Background()
+	src := &DefaultStore{DB: openDB(t)}
+	if err := src.User().Create(ctx, &models.User{Name: "a", Email: "a@8x8.co.tz"}); err != nil {
+		t.Fatal(err)
+	}
+	g := &models.Game{Black: "a@8x8.co.tz", Moves: []*models.Move{mv(t, "11-15")}}
+	if err := src.Games().Create(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := src.Backup(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dst := &DefaultStore{DB: openDB(t)}
+	if err := dst.Restore(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dst.User().Get(ctx, "a@8x8.co.tz"); err != nil {
+		t.Errorf("expected the user to be restored got %v", err)
+	}
+	got, err := dst.Games().Get(ctx, g.Id)
+	if err != nil || len(got.Moves) != 1 {
+		t.Errorf("expected the game to be restored got %v %v", got, err)
+	}
+	if err := dst.Restore(bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrNotEmpty) {
+		t.Errorf("expected a restore over data to fail got %v", err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	s.User().Create(ctx, &models.User{Name: "a", Email: "a@8x8.co.tz"})
+	g := &models.Game{Black: "a@8x8.co.tz", White: "b@8x8.co.tz", Moves: []*models.Move{mv(t, "11-15")}}
+	if err := s.Games().Create(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Export(ctx, s, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var line map[string]map[string]interface{}
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			t.Fatalf("%s: %v", sc.Text(), err)
+		}
+		for kind, v := range line {
+			kinds = append(kinds, kind)
+			if kind == "game" && (v["id"] != g.Id || len(v["moves"].([]interface{})) != 1) {
+				t.Errorf("expected the game with its moves got %v", v)
+			}
+		}
+	}
+	if len(kinds) != 2 || kinds[0] != "user" || kinds[1] != "game" {
+		t.Errorf("expected a user then a game got %v", kinds)
+	}
+}
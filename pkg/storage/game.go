@@ -0,0 +1,501 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+const (
+	game     = "game"
+	player   = "player"
+	event    = "move"
+	snapshot = "snapshot"
+)
+
+// DefaultSnapshotInterval is the number of moves between the snapshots of a
+// game's position.
+const DefaultSnapshotInterval = 20
+
+// ErrVariant is returned when saving a game of rules check does not know.
+var ErrVariant = errors.New("storage: unknown variant")
+
+// Games keeps the games played on the server, so that players can leave and
+// come back to them. The moves of a game are kept as a log that is only ever
+// appended to, the position is rebuilt by playing them again.
+type Games interface {
+	// Create saves a new game and gives it an id. The game may start from a
+	// setup position and with moves already played, as ballot games do, the
+	// moves of its ballot coming first. See Pair for the second game of a
+	// ballot.
+	Create(ctx context.Context, g *models.Game) error
+	// Get returns the game with all its moves.
+	Get(ctx context.Context, id string) (*models.Game, error)
+	// AppendMove plays m in the game and returns its entry in the log. Moves
+	// that are not legal are rejected with check.ErrIllegalMove, and moves
+	// after the end of the game with check.ErrGameOver.
+	AppendMove(ctx context.Context, id string, m *models.Move) (*models.MoveEvent, error)
+	// Events returns the log of the game's moves after the one numbered
+	// after, zero for all of them.
+	Events(ctx context.Context, id string, after int64) ([]*models.MoveEvent, error)
+	// Load returns the game in play, rebuilt from the latest snapshot of its
+	// position and the moves after it. Its history starts at the snapshot,
+	// use Get and Replay for the whole game.
+	Load(ctx context.Context, id string) (*check.Game, error)
+	// Finish ends the game for reasons the moves do not show, such as a
+	// resignation or an agreed draw.
+	Finish(ctx context.Context, id string, result check.Result, reason check.Reason) error
+	// List returns the games of the player with the given email, newest
+	// first, skipping offset games and returning at most limit.
+	List(ctx context.Context, email string, offset, limit int) ([]*models.Game, error)
+	// Walk calls fn with every game and its moves, in the order of their ids.
+	Walk(ctx context.Context, fn func(*models.Game) error) error
+}
+
+// Replay returns g played out from its starting position.
+func Replay(g *models.Game) (*check.Game, error) {
+	play, err := start(g)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range g.Moves {
+		if err := play.Apply(m); err != nil {
+			return nil, err
+		}
+	}
+	if err := fixBallot(g, play, 0); err != nil {
+		return nil, err
+	}
+	return play, nil
+}
+
+// fixBallot keeps the ballot moves of g from being taken back in play, which
+// was played from the position after the first played moves of g.
+func fixBallot(g *models.Game, play *check.Game, played int64) error {
+	if g.Ballot == nil {
+		return nil
+	}
+	return play.FixBallot(check.BallotFrom(g.Ballot), int(played))
+}
+
+// Pair returns the second game of the pair g starts, played from the same
+// ballot with the players swapping colours. It is saved with Create.
+func Pair(g *models.Game) (*models.Game, error) {
+	if g.Ballot == nil {
+		return nil, fmt.Errorf("%w: game %s has no ballot", check.ErrBallot, g.Id)
+	}
+	b := proto.Clone(g.Ballot).(*models.Ballot)
+	p := &models.Game{
+		Variant: g.Variant,
+		Black:   g.White,
+		White:   g.Black,
+		Setup:   g.Setup,
+		Ballot:  b,
+	}
+	for _, m := range b.Moves {
+		p.Moves = append(p.Moves, proto.Clone(m).(*models.Move))
+	}
+	return p, nil
+}
+
+// start returns g at its starting position.
+func start(g *models.Game) (*check.Game, error) {
+	v := check.LookupVariant(g.Variant)
+	if v == nil {
+		return nil, fmt.Errorf("%w %q", ErrVariant, g.Variant)
+	}
+	if g.Setup == "" {
+		return v.NewGame(), nil
+	}
+	b, err := v.ParseFEN(g.Setup)
+	if err != nil {
+		return nil, err
+	}
+	return check.NewGameFrom(b), nil
+}
+
+// restartable reports whether a game restarted from the current position of
+// play goes on exactly as play would, which is when snapshots are taken.
+func restartable(play *check.Game) bool {
+	return !play.Capturing() && play.QuietMoves() == 0
+}
+
+// seq formats the number of a move so that the keys of the log sort in the
+// order the moves were played.
+func seq(n int64) string {
+	return fmt.Sprintf("%016x", n)
+}
+
+// newID returns a random game id.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// newest orders the keys of the player index with the latest game first.
+func newest(g *models.Game) (string, error) {
+	t, err := ptypes.Timestamp(g.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%016x", math.MaxInt64-t.UnixNano()), nil
+}
+
+// players returns the distinct emails of the players of g, leaving out
+// players without one such as bots.
+func players(g *models.Game) []string {
+	var ls []string
+	for _, email := range []string{g.Black, g.White} {
+		if email != "" && (len(ls) == 0 || ls[0] != email) {
+			ls = append(ls, email)
+		}
+	}
+	return ls
+}
+
+// newGame gives g an id and sets its times and result, checking the moves it
+// starts with. It returns the log of these moves.
+func newGame(g *models.Game) ([]*models.MoveEvent, error) {
+	play, err := start(g)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	g.Id = id
+	g.CreatedAt = ptypes.TimestampNow()
+	g.UpdatedAt = g.CreatedAt
+	events := make([]*models.MoveEvent, len(g.Moves))
+	for i, m := range g.Moves {
+		events[i] = &models.MoveEvent{
+			Game:      id,
+			Seq:       int64(i + 1),
+			Move:      m,
+			Player:    bool(play.Turn()),
+			CreatedAt: g.CreatedAt,
+		}
+		if err := play.Apply(m); err != nil {
+			return nil, err
+		}
+	}
+	if err := fixBallot(g, play, 0); err != nil {
+		return nil, err
+	}
+	g.Result, g.Reason = int32(play.Result()), int32(play.Reason())
+	return events, nil
+}
+
+// header returns g without its moves, which are kept in the log.
+func header(g *models.Game) *models.Game {
+	h := proto.Clone(g).(*models.Game)
+	h.Moves = nil
+	return h
+}
+
+// playMove applies m to play, the game g after n moves, and returns the entry
+// of m in the log. The result of g is updated.
+func playMove(g *models.Game, play *check.Game, n int64, m *models.Move) (*models.MoveEvent, error) {
+	if g.Result != int32(check.Ongoing) {
+		return nil, check.ErrGameOver
+	}
+	side := play.Turn()
+	if err := play.Apply(m); err != nil {
+		return nil, err
+	}
+	e := &models.MoveEvent{
+		Game:      g.Id,
+		Seq:       n + 1,
+		Move:      m,
+		Player:    bool(side),
+		CreatedAt: ptypes.TimestampNow(),
+	}
+	g.Result, g.Reason = int32(play.Result()), int32(play.Reason())
+	g.UpdatedAt = e.CreatedAt
+	return e, nil
+}
+
+// finish ends g with result for reason.
+func finish(g *models.Game, result check.Result, reason check.Reason) error {
+	if g.Result != int32(check.Ongoing) {
+		return check.ErrResultSet
+	}
+	g.Result, g.Reason = int32(result), int32(reason)
+	g.UpdatedAt = ptypes.TimestampNow()
+	return nil
+}
+
+type badgerGames struct {
+	db *badger.DB
+	// every is the number of moves between snapshots.
+	every int64
+}
+
+func (b *badgerGames) Create(ctx context.Context, g *models.Game) error {
+	events, err := newGame(g)
+	if err != nil {
+		return err
+	}
+	order, err := newest(g)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(txn *badger.Txn) error {
+		for _, email := range players(g) {
+			if err := txn.Set(key(player, email, order, g.Id), nil); err != nil {
+				return err
+			}
+		}
+		for _, e := range events {
+			if err := set(txn, key(game, g.Id, event, seq(e.Seq)), e); err != nil {
+				return err
+			}
+		}
+		return set(txn, key(game, g.Id), header(g))
+	})
+}
+
+func (b *badgerGames) Get(ctx context.Context, id string) (g *models.Game, err error) {
+	err = b.db.View(func(txn *badger.Txn) error {
+		g, err = getGame(txn, id)
+		if err != nil {
+			return err
+		}
+		return events(txn, id, 0, func(e *models.MoveEvent) error {
+			g.Moves = append(g.Moves, e.Move)
+			return nil
+		})
+	})
+	return
+}
+
+func (b *badgerGames) AppendMove(ctx context.Context, id string, m *models.Move) (e *models.MoveEvent, err error) {
+	err = b.db.Update(func(txn *badger.Txn) error {
+		g, err := getGame(txn, id)
+		if err != nil {
+			return err
+		}
+		play, last, n, err := load(txn, g)
+		if err != nil {
+			return err
+		}
+		e, err = playMove(g, play, n, m)
+		if err != nil {
+			return err
+		}
+		if err := set(txn, key(game, id, event, seq(e.Seq)), e); err != nil {
+			return err
+		}
+		if e.Seq-last >= b.interval() && restartable(play) {
+			err := set(txn, key(game, id, snapshot, seq(e.Seq)), play.Board().Snapshot())
+			if err != nil {
+				return err
+			}
+		}
+		return set(txn, key(game, id), g)
+	})
+	return
+}
+
+func (b *badgerGames) interval() int64 {
+	if b.every > 0 {
+		return b.every
+	}
+	return DefaultSnapshotInterval
+}
+
+func (b *badgerGames) Events(ctx context.Context, id string, after int64) (ls []*models.MoveEvent, err error) {
+	err = b.db.View(func(txn *badger.Txn) error {
+		if _, err := getGame(txn, id); err != nil {
+			return err
+		}
+		return events(txn, id, after, func(e *models.MoveEvent) error {
+			ls = append(ls, e)
+			return nil
+		})
+	})
+	return
+}
+
+func (b *badgerGames) Load(ctx context.Context, id string) (play *check.Game, err error) {
+	err = b.db.View(func(txn *badger.Txn) error {
+		g, err := getGame(txn, id)
+		if err != nil {
+			return err
+		}
+		play, _, _, err = load(txn, g)
+		return err
+	})
+	return
+}
+
+func (b *badgerGames) Finish(ctx context.Context, id string, result check.Result, reason check.Reason) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		g, err := getGame(txn, id)
+		if err != nil {
+			return err
+		}
+		if err := finish(g, result, reason); err != nil {
+			return err
+		}
+		return set(txn, key(game, id), g)
+	})
+}
+
+func (b *badgerGames) List(ctx context.Context, email string, offset, limit int) (ls []*models.Game, err error) {
+	err = b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		prefix := key(player, email, "")
+		for it.Seek(prefix); it.ValidForPrefix(prefix) && len(ls) < limit; it.Next() {
+			if offset > 0 {
+				offset--
+				continue
+			}
+			k := it.Item().Key()
+			g, err := getGame(txn, string(k[bytes.LastIndexByte(k, '/')+1:]))
+			if err != nil {
+				return err
+			}
+			ls = append(ls, g)
+		}
+		return nil
+	})
+	return
+}
+
+func (b *badgerGames) Walk(ctx context.Context, fn func(*models.Game) error) error {
+	// One read transaction sees every game as it was when the walk started,
+	// fn is called as they are read so that only one is held at a time.
+	return b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		prefix := key(game, "")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if bytes.IndexByte(it.Item().Key()[len(prefix):], '/') != -1 {
+				// The log and snapshots of the game.
+				continue
+			}
+			g := &models.Game{}
+			err := it.Item().Value(func(val []byte) error {
+				return proto.Unmarshal(val, g)
+			})
+			if err != nil {
+				return err
+			}
+			err = events(txn, g.Id, 0, func(e *models.MoveEvent) error {
+				g.Moves = append(g.Moves, e.Move)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if err := fn(g); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// load rebuilds the game in play from its latest snapshot and the moves after
+// it. It returns the number of the move of the snapshot, zero when there is
+// none, and of the last move.
+func load(txn *badger.Txn, g *models.Game) (play *check.Game, last, n int64, err error) {
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = true
+	it := txn.NewIterator(opts)
+	prefix := key(game, g.Id, snapshot, "")
+	it.Seek(append(prefix, 0xff))
+	if it.ValidForPrefix(prefix) {
+		k := it.Item().Key()
+		last, err = strconv.ParseInt(string(k[len(prefix):]), 16, 64)
+		if err == nil {
+			b := &models.Board{}
+			err = it.Item().Value(func(val []byte) error {
+				return proto.Unmarshal(val, b)
+			})
+			play = check.NewGameFrom(check.Restore(b))
+		}
+	}
+	it.Close()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if play == nil {
+		play, err = start(g)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	n = last
+	err = events(txn, g.Id, last, func(e *models.MoveEvent) error {
+		n = e.Seq
+		return play.Apply(e.Move)
+	})
+	if err == nil {
+		err = fixBallot(g, play, last)
+	}
+	return play, last, n, err
+}
+
+// events calls fn with the moves of the game id after the one numbered after,
+// in the order they were played.
+func events(txn *badger.Txn, id string, after int64, fn func(*models.MoveEvent) error) error {
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	prefix := key(game, id, event, "")
+	for it.Seek(key(game, id, event, seq(after+1))); it.ValidForPrefix(prefix); it.Next() {
+		e := &models.MoveEvent{}
+		err := it.Item().Value(func(val []byte) error {
+			return proto.Unmarshal(val, e)
+		})
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getGame(txn *badger.Txn, id string) (*models.Game, error) {
+	it, err := txn.Get(key(game, id))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	g := &models.Game{}
+	err = it.Value(func(val []byte) error {
+		return proto.Unmarshal(val, g)
+	})
+	return g, err
+}
+
+func set(txn *badger.Txn, k []byte, m proto.Message) error {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return txn.Set(k, b)
+}
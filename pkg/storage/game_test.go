@@ -0,0 +1,314 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+)
+
+func openDB(t *testing.T) *badger.DB {
+	t.Helper()
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func mv(t *testing.T, s string) *models.Move {
+	t.Helper()
+	m, err := check.ParseMove(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func testGames(t *testing.T, store Store) {
+	ctx := context.Background()
+	games := store.Games()
+	g := &models.Game{Black: "a@8x8.co.tz", White: "b@8x8.co.tz"}
+	if err := games.Create(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	if g.Id == "" || g.CreatedAt == nil {
+		t.Fatalf("expected the game to be given an id got %v", g)
+	}
+	if _, err := games.AppendMove(ctx, g.Id, mv(t, "11-15")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := games.AppendMove(ctx, g.Id, mv(t, "11-15")); !errors.Is(err, check.ErrIllegalMove) {
+		t.Errorf("expected an illegal move got %v", err)
+	}
+	if _, err := games.AppendMove(ctx, g.Id, mv(t, "23-19")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := games.Get(ctx, g.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	play, err := Replay(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Moves) != 2 || play.Turn() != check.Black {
+		t.Errorf("expected two moves got %v", got.Moves)
+	}
+	if err := games.Finish(ctx, g.Id, check.WhiteWins, check.Resignation); err != nil {
+		t.Fatal(err)
+	}
+	if err := games.Finish(ctx, g.Id, check.Draw, check.Agreement); !errors.Is(err, check.ErrResultSet) {
+		t.Errorf("expected the result to be kept got %v", err)
+	}
+	if _, err := games.AppendMove(ctx, g.Id, mv(t, "8-11")); !errors.Is(err, check.ErrGameOver) {
+		t.Errorf("expected no moves after the end got %v", err)
+	}
+	if _, err := games.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found got %v", err)
+	}
+	for _, bad := range []*models.Game{
+		{Variant: "chess"},
+		{Setup: "X:W1"},
+		{Moves: []*models.Move{mv(t, "21-17")}},
+	} {
+		if err := games.Create(ctx, bad); err == nil {
+			t.Errorf("expected %v to be rejected", bad)
+		}
+	}
+}
+
+func testBallot(t *testing.T, store Store) {
+	ctx := context.Background()
+	games := store.Games()
+	ballots, err := check.ReadBallots(check.English, strings.NewReader("11-15 23-19 8-11 Old Fourteenth\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := ballots[0]
+	g := &models.Game{Black: "a@8x8.co.tz", White: "b@8x8.co.tz", Moves: b.Moves, Ballot: b.Model()}
+	if err := games.Create(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := games.AppendMove(ctx, g.Id, mv(t, "22-17")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := games.Get(ctx, g.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Ballot.GetNumber() != 1 || got.Ballot.GetName() != "Old Fourteenth" {
+		t.Fatalf("expected the ballot to be kept got %v", got.Ballot)
+	}
+	replayed, err := Replay(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := games.Load(ctx, g.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, play := range []*check.Game{replayed, loaded} {
+		if rb, ok := play.Ballot(); !ok || rb.Number != 1 {
+			t.Errorf("expected ballot 1 got %v", rb)
+		}
+	}
+	if !replayed.Undo() || replayed.Undo() {
+		t.Error("expected only the move after the ballot to be taken back")
+	}
+	pair, err := Pair(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := games.Create(ctx, pair); err != nil {
+		t.Fatal(err)
+	}
+	if pair.Black != g.White || pair.White != g.Black || len(pair.Moves) != len(b.Moves) {
+		t.Errorf("expected the colours swapped from the ballot got %v", pair)
+	}
+	if _, err := Pair(&models.Game{}); !errors.Is(err, check.ErrBallot) {
+		t.Errorf("expected no pair without a ballot got %v", err)
+	}
+	bad := &models.Game{Moves: []*models.Move{mv(t, "9-13")}, Ballot: b.Model()}
+	if err := games.Create(ctx, bad); !errors.Is(err, check.ErrBallot) {
+		t.Errorf("expected moves of another opening to be rejected got %v", err)
+	}
+}
+
+func testMoveLog(t *testing.T, store Store) {
+	ctx := context.Background()
+	games := store.Games()
+	g := &models.Game{Black: "a@8x8.co.tz", Moves: []*models.Move{mv(t, "11-15"), mv(t, "23-19")}}
+	if err := games.Create(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	play := check.NewGame()
+	for _, m := range g.Moves {
+		play.Apply(m)
+	}
+	for !play.Over() && len(play.History()) < 120 {
+		legal := play.LegalMoves()
+		m := legal[r.Intn(len(legal))]
+		if err := play.Apply(m); err != nil {
+			t.Fatal(err)
+		}
+		e, err := games.AppendMove(ctx, g.Id, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Seq != int64(len(play.History())) || e.Player == bool(play.Turn()) {
+			t.Fatalf("unexpected event %v after %d moves", e, len(play.History()))
+		}
+		loaded, err := games.Load(ctx, g.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Key() != play.Key() || loaded.Result() != play.Result() {
+			t.Fatalf("move %d: expected %s got %s", e.Seq, play.Board().FEN(), loaded.Board().FEN())
+		}
+	}
+	got, err := games.Get(ctx, g.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := Replay(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Key() != play.Key() || got.Result != int32(play.Result()) {
+		t.Errorf("expected the game to be replayed got %s", replayed.Board().FEN())
+	}
+	events, err := games.Events(ctx, g.Id, int64(len(got.Moves)-2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[1].Seq != int64(len(got.Moves)) {
+		t.Errorf("expected the last two moves got %v", events)
+	}
+}
+
+func testListGames(t *testing.T, store Store) {
+	ctx := context.Background()
+	games := store.Games()
+	var ids []string
+	for i := 0; i < 5; i++ {
+		g := &models.Game{Black: "a@8x8.co.tz", White: "b@8x8.co.tz"}
+		if i%2 == 1 {
+			g.Black, g.White = g.White, "c@8x8.co.tz"
+		}
+		if err := games.Create(ctx, g); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, g.Id)
+		time.Sleep(time.Millisecond)
+	}
+	list := func(email string, offset, limit int) []string {
+		t.Helper()
+		ls, err := games.List(ctx, email, offset, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, g := range ls {
+			got = append(got, g.Id)
+		}
+		return got
+	}
+	if got := list("b@8x8.co.tz", 0, 10); len(got) != 5 || got[0] != ids[4] || got[4] != ids[0] {
+		t.Errorf("expected all games newest first got %v", got)
+	}
+	if got := list("b@8x8.co.tz", 1, 2); len(got) != 2 || got[0] != ids[3] || got[1] != ids[2] {
+		t.Errorf("expected the second page got %v", got)
+	}
+	if got := list("c@8x8.co.tz", 0, 10); len(got) != 2 || got[0] != ids[3] {
+		t.Errorf("expected the games of c got %v", got)
+	}
+	if got := list("a@8x8.co", 0, 10); len(got) != 0 {
+		t.Errorf("expected no games for a prefix of an email got %v", got)
+	}
+}
+
+func testWalk(t *testing.T, store Store) {
+	ctx := context.Background()
+	games := store.Games()
+	moves := map[string]int{}
+	for i, opening := range [][]string{{"11-15"}, {}, {"9-13", "22-18"}} {
+		g := &models.Game{Black: "a@8x8.co.tz"}
+		for _, s := range opening {
+			g.Moves = append(g.Moves, mv(t, s))
+		}
+		if err := games.Create(ctx, g); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			if _, err := games.AppendMove(ctx, g.Id, mv(t, "12-16")); err != nil {
+				t.Fatal(err)
+			}
+			moves[g.Id] = 1
+		} else {
+			moves[g.Id] = len(opening)
+		}
+	}
+	var ids []string
+	err := games.Walk(ctx, func(g *models.Game) error {
+		if len(g.Moves) != moves[g.Id] {
+			t.Errorf("%s: expected %d moves got %d", g.Id, moves[g.Id], len(g.Moves))
+		}
+		ids = append(ids, g.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || !sort.StringsAreSorted(ids) {
+		t.Errorf("expected every game by id got %v", ids)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	games := (&DefaultStore{DB: db, SnapshotInterval: 2}).Games()
+	g := &models.Game{}
+	if err := games.Create(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"11-15", "23-19", "8-11", "22-17", "9-13"} {
+		if _, err := games.AppendMove(ctx, g.Id, mv(t, s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []int64
+	db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		prefix := key(game, g.Id, snapshot, "")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			n, _ := strconv.ParseInt(string(it.Item().Key()[len(prefix):]), 16, 64)
+			got = append(got, n)
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(got, []int64{2, 4}) {
+		t.Errorf("expected snapshots after moves 2 and 4 got %v", got)
+	}
+	play, err := games.Load(ctx, g.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The history starts at the last snapshot.
+	if h := play.History(); len(h) != 1 || check.Notation(h[0]) != "9-13" {
+		t.Errorf("expected the game to be loaded from the last snapshot got %v", h)
+	}
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+	"github.com/golang/protobuf/proto"
+)
+
+// MemoryStore is a Store that keeps everything in memory, for tests and
+// development. It behaves like DefaultStore, values are copied in and out so
+// callers never share them with the store.
+type MemoryStore struct {
+	mu    sync.Mutex
+	users map[string]*models.User
+	games map[string]*memoryGame
+}
+
+type memoryGame struct {
+	header *models.Game
+	events []*models.MoveEvent
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users: make(map[string]*models.User),
+		games: make(map[string]*memoryGame),
+	}
+}
+
+func (m *MemoryStore) User() User {
+	return &memoryUSR{m}
+}
+
+func (m *MemoryStore) Games() Games {
+	return &memoryGames{m}
+}
+
+type memoryUSR struct {
+	*MemoryStore
+}
+
+func (m *memoryUSR) Create(ctx context.Context, usr *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if merge(usr, m.users[usr.Email]) {
+		m.users[usr.Email] = proto.Clone(usr).(*models.User)
+	}
+	return nil
+}
+
+func (m *memoryUSR) Get(ctx context.Context, email string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usr, ok := m.users[email]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(usr).(*models.User), nil
+}
+
+func (m *memoryUSR) List(ctx context.Context) ([]*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	emails := make([]string, 0, len(m.users))
+	for email := range m.users {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	var ls []*models.User
+	for _, email := range emails {
+		ls = append(ls, proto.Clone(m.users[email]).(*models.User))
+	}
+	return ls, nil
+}
+
+type memoryGames struct {
+	*MemoryStore
+}
+
+func (m *memoryGames) Create(ctx context.Context, g *models.Game) error {
+	events, err := newGame(g)
+	if err != nil {
+		return err
+	}
+	for i, e := range events {
+		events[i] = proto.Clone(e).(*models.MoveEvent)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.games[g.Id] = &memoryGame{header: header(g), events: events}
+	return nil
+}
+
+func (m *memoryGames) Get(ctx context.Context, id string) (*models.Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mg, ok := m.games[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	g := proto.Clone(mg.header).(*models.Game)
+	for _, e := range mg.events {
+		g.Moves = append(g.Moves, proto.Clone(e.Move).(*models.Move))
+	}
+	return g, nil
+}
+
+func (m *memoryGames) AppendMove(ctx context.Context, id string, mv *models.Move) (*models.MoveEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mg, ok := m.games[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	play, err := mg.load()
+	if err != nil {
+		return nil, err
+	}
+	g := proto.Clone(mg.header).(*models.Game)
+	e, err := playMove(g, play, int64(len(mg.events)), proto.Clone(mv).(*models.Move))
+	if err != nil {
+		return nil, err
+	}
+	mg.header = g
+	mg.events = append(mg.events, e)
+	return proto.Clone(e).(*models.MoveEvent), nil
+}
+
+func (m *memoryGames) Events(ctx context.Context, id string, after int64) ([]*models.MoveEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mg, ok := m.games[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	var ls []*models.MoveEvent
+	for _, e := range mg.events {
+		if e.Seq > after {
+			ls = append(ls, proto.Clone(e).(*models.MoveEvent))
+		}
+	}
+	return ls, nil
+}
+
+func (m *memoryGames) Load(ctx context.Context, id string) (*check.Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mg, ok := m.games[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return mg.load()
+}
+
+func (m *memoryGames) Finish(ctx context.Context, id string, result check.Result, reason check.Reason) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mg, ok := m.games[id]
+	if !ok {
+		return ErrNotFound
+	}
+	g := proto.Clone(mg.header).(*models.Game)
+	if err := finish(g, result, reason); err != nil {
+		return err
+	}
+	mg.header = g
+	return nil
+}
+
+func (m *memoryGames) List(ctx context.Context, email string, offset, limit int) ([]*models.Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Order the games as the keys of the player index of badger are.
+	type entry struct {
+		key string
+		g   *models.Game
+	}
+	var all []entry
+	for _, mg := range m.games {
+		for _, p := range players(mg.header) {
+			if p != email {
+				continue
+			}
+			order, err := newest(mg.header)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, entry{order + "/" + mg.header.Id, mg.header})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].key < all[j].key
+	})
+	if offset < 0 {
+		offset = 0
+	}
+	var ls []*models.Game
+	for i := offset; i < len(all) && len(ls) < limit; i++ {
+		ls = append(ls, proto.Clone(all[i].g).(*models.Game))
+	}
+	return ls, nil
+}
+
+func (m *memoryGames) Walk(ctx context.Context, fn func(*models.Game) error) error {
+	m.mu.Lock()
+	ids := make([]string, 0, len(m.games))
+	for id := range m.games {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+	sort.Strings(ids)
+	for _, id := range ids {
+		g, err := m.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := fn(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load plays the game from the start, the whole log is at hand.
+func (mg *memoryGame) load() (*check.Game, error) {
+	play, err := start(mg.header)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range mg.events {
+		if err := play.Apply(e.Move); err != nil {
+			return nil, err
+		}
+	}
+	if err := fixBallot(mg.header, play, 0); err != nil {
+		return nil, err
+	}
+	return play, nil
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gernest/8x8/pkg/models"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
+	// The pure Go SQLite driver, registered as sqlite.
+	_ "modernc.org/sqlite"
+)
+
+// migrations are the changes made to the schema of SQL stores, in order. A
+// database records how many it has applied in schema_migrations, so new
+// changes are appended and the old ones are never edited. The statements only
+// use what SQLite and Postgres have in common.
+var migrations = []string{
+	`CREATE TABLE users (
+		email TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		picture TEXT NOT NULL,
+		created_at BIGINT,
+		updated_at BIGINT
+	)`,
+	`CREATE TABLE games (
+		id TEXT PRIMARY KEY,
+		variant TEXT NOT NULL,
+		black TEXT NOT NULL,
+		white TEXT NOT NULL,
+		setup TEXT NOT NULL,
+		result INTEGER NOT NULL,
+		reason INTEGER NOT NULL,
+		created_at BIGINT NOT NULL,
+		updated_at BIGINT NOT NULL,
+		ballot TEXT
+	)`,
+	`CREATE INDEX games_black ON games (black, created_at)`,
+	`CREATE INDEX games_white ON games (white, created_at)`,
+	`CREATE TABLE moves (
+		game TEXT NOT NULL REFERENCES games (id),
+		seq BIGINT NOT NULL,
+		notation TEXT NOT NULL,
+		move TEXT NOT NULL,
+		player BOOLEAN NOT NULL,
+		created_at BIGINT NOT NULL,
+		PRIMARY KEY (game, seq)
+	)`,
+	`CREATE TABLE snapshots (
+		game TEXT NOT NULL REFERENCES games (id),
+		seq BIGINT NOT NULL,
+		board TEXT NOT NULL,
+		PRIMARY KEY (game, seq)
+	)`,
+}
+
+// SQLStore is a Store kept in a SQL database, a SQLite file for a single
+// server or a Postgres database shared by several. Times are stored as Unix
+// nanoseconds and moves as the JSON of the protobuf models, next to their
+// notation for reports.
+type SQLStore struct {
+	DB *sql.DB
+	// SnapshotInterval is the number of moves between the snapshots of the
+	// position of a game, DefaultSnapshotInterval when zero.
+	SnapshotInterval int
+}
+
+// OpenSQL opens the database at source with the database/sql driver called
+// driver, such as sqlite, and brings its schema up to date.
+func OpenSQL(ctx context.Context, driver, source string) (*SQLStore, error) {
+	db, err := sql.Open(driver, source)
+	if err != nil {
+		return nil, err
+	}
+	if driver == "sqlite" {
+		// SQLite allows one writer at a time, and every connection to an in
+		// memory database opens a new one.
+		db.SetMaxOpenConns(1)
+	}
+	s := &SQLStore{DB: db}
+	if err := s.Migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Migrate applies the migrations the database does not have yet, each one in
+// its own transaction.
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	_, err := s.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+	var version int
+	err = s.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return err
+	}
+	for ; version < len(migrations); version++ {
+		tx, err := s.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[version]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`,
+			version+1, time.Now().UnixNano())
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLStore) User() User {
+	return &sqlUSR{db: s.DB}
+}
+
+func (s *SQLStore) Games() Games {
+	return &sqlGames{db: s.DB, every: int64(s.SnapshotInterval)}
+}
+
+// scanner is a *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func nanos(t *timestamp.Timestamp) (sql.NullInt64, error) {
+	if t == nil {
+		return sql.NullInt64{}, nil
+	}
+	tm, err := ptypes.Timestamp(t)
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+	return sql.NullInt64{Int64: tm.UnixNano(), Valid: true}, nil
+}
+
+func fromNanos(n sql.NullInt64) *timestamp.Timestamp {
+	if !n.Valid {
+		return nil
+	}
+	t, _ := ptypes.TimestampProto(time.Unix(0, n.Int64))
+	return t
+}
+
+type sqlUSR struct {
+	db *sql.DB
+}
+
+func (s *sqlUSR) Create(ctx context.Context, usr *models.User) error {
+	old, err := s.Get(ctx, usr.Email)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if !merge(usr, old) {
+		return nil
+	}
+	created, err := nanos(usr.CreatedAt)
+	if err != nil {
+		return err
+	}
+	updated, err := nanos(usr.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO users (email, name, picture, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (email) DO UPDATE SET name = excluded.name, picture = excluded.picture,
+			created_at = excluded.created_at, updated_at = excluded.updated_at`,
+		usr.Email, usr.Name, usr.Picture, created, updated)
+	return err
+}
+
+func (s *sqlUSR) Get(ctx context.Context, email string) (*models.User, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT email, name, picture, created_at, updated_at
+		FROM users WHERE email = $1`, email)
+	usr, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return usr, err
+}
+
+func (s *sqlUSR) List(ctx context.Context) ([]*models.User, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT email, name, picture, created_at, updated_at
+		FROM users ORDER BY email`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ls []*models.User
+	for rows.Next() {
+		usr, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		ls = append(ls, usr)
+	}
+	return ls, rows.Err()
+}
+
+func scanUser(row scanner) (*models.User, error) {
+	usr := &models.User{}
+	var created, updated sql.NullInt64
+	if err := row.Scan(&usr.Email, &usr.Name, &usr.Picture, &created, &updated); err != nil {
+		return nil, err
+	}
+	usr.CreatedAt, usr.UpdatedAt = fromNanos(created), fromNanos(updated)
+	return usr, nil
+}
@@ -0,0 +1,349 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/models"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+const gameColumns = `id, variant, black, white, setup, result, reason, created_at, updated_at, ballot`
+
+type sqlGames struct {
+	db *sql.DB
+	// every is the number of moves between snapshots.
+	every int64
+}
+
+func (s *sqlGames) Create(ctx context.Context, g *models.Game) error {
+	events, err := newGame(g)
+	if err != nil {
+		return err
+	}
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		created, err := nanos(g.CreatedAt)
+		if err != nil {
+			return err
+		}
+		var ballot sql.NullString
+		if g.Ballot != nil {
+			ballot.String, err = (&jsonpb.Marshaler{}).MarshalToString(g.Ballot)
+			if err != nil {
+				return err
+			}
+			ballot.Valid = true
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO games (`+gameColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			g.Id, g.Variant, g.Black, g.White, g.Setup, g.Result, g.Reason, created, created, ballot)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := insertEvent(ctx, tx, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlGames) Get(ctx context.Context, id string) (*models.Game, error) {
+	g, err := getRow(ctx, s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	err = queryEvents(ctx, s.db, id, 0, func(e *models.MoveEvent) error {
+		g.Moves = append(g.Moves, e.Move)
+		return nil
+	})
+	return g, err
+}
+
+func (s *sqlGames) AppendMove(ctx context.Context, id string, m *models.Move) (e *models.MoveEvent, err error) {
+	err = s.tx(ctx, func(tx *sql.Tx) error {
+		g, err := getRow(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		play, last, n, err := loadRows(ctx, tx, g)
+		if err != nil {
+			return err
+		}
+		e, err = playMove(g, play, n, m)
+		if err != nil {
+			return err
+		}
+		if err := insertEvent(ctx, tx, e); err != nil {
+			return err
+		}
+		if e.Seq-last >= s.interval() && restartable(play) {
+			board, err := (&jsonpb.Marshaler{}).MarshalToString(play.Board().Snapshot())
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `INSERT INTO snapshots (game, seq, board) VALUES ($1, $2, $3)`,
+				id, e.Seq, board)
+			if err != nil {
+				return err
+			}
+		}
+		return updateRow(ctx, tx, g)
+	})
+	return
+}
+
+func (s *sqlGames) interval() int64 {
+	if s.every > 0 {
+		return s.every
+	}
+	return DefaultSnapshotInterval
+}
+
+func (s *sqlGames) Events(ctx context.Context, id string, after int64) (ls []*models.MoveEvent, err error) {
+	if _, err := getRow(ctx, s.db, id); err != nil {
+		return nil, err
+	}
+	err = queryEvents(ctx, s.db, id, after, func(e *models.MoveEvent) error {
+		ls = append(ls, e)
+		return nil
+	})
+	return
+}
+
+func (s *sqlGames) Load(ctx context.Context, id string) (play *check.Game, err error) {
+	err = s.tx(ctx, func(tx *sql.Tx) error {
+		g, err := getRow(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		play, _, _, err = loadRows(ctx, tx, g)
+		return err
+	})
+	return
+}
+
+func (s *sqlGames) Finish(ctx context.Context, id string, result check.Result, reason check.Reason) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		g, err := getRow(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := finish(g, result, reason); err != nil {
+			return err
+		}
+		return updateRow(ctx, tx, g)
+	})
+}
+
+func (s *sqlGames) List(ctx context.Context, email string, offset, limit int) ([]*models.Game, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT `+gameColumns+` FROM games
+		WHERE black = $1 OR white = $1
+		ORDER BY created_at DESC, id LIMIT $2 OFFSET $3`, email, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ls []*models.Game
+	for rows.Next() {
+		g, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		ls = append(ls, g)
+	}
+	return ls, rows.Err()
+}
+
+// walkPage is the number of games Walk reads at a time.
+var walkPage = 100
+
+func (s *sqlGames) Walk(ctx context.Context, fn func(*models.Game) error) error {
+	// The games are read a page at a time, SQLite has a single connection
+	// which fn may need.
+	after := ""
+	for {
+		games, err := s.page(ctx, after)
+		if err != nil {
+			return err
+		}
+		for _, g := range games {
+			err := queryEvents(ctx, s.db, g.Id, 0, func(e *models.MoveEvent) error {
+				g.Moves = append(g.Moves, e.Move)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if err := fn(g); err != nil {
+				return err
+			}
+		}
+		if len(games) < walkPage {
+			return nil
+		}
+		after = games[len(games)-1].Id
+	}
+}
+
+// page returns the next games of the walk, with ids after the given one.
+func (s *sqlGames) page(ctx context.Context, after string) ([]*models.Game, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+gameColumns+` FROM games
+		WHERE id > $1 ORDER BY id LIMIT $2`, after, walkPage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var games []*models.Game
+	for rows.Next() {
+		g, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+	return games, rows.Err()
+}
+
+// tx runs fn in a transaction, committed when fn succeeds.
+func (s *sqlGames) tx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is a *sql.DB or *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func getRow(ctx context.Context, q querier, id string) (*models.Game, error) {
+	g, err := scanGame(q.QueryRowContext(ctx, `SELECT `+gameColumns+` FROM games WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return g, err
+}
+
+func scanGame(row scanner) (*models.Game, error) {
+	g := &models.Game{}
+	var created, updated sql.NullInt64
+	var ballot sql.NullString
+	err := row.Scan(&g.Id, &g.Variant, &g.Black, &g.White, &g.Setup, &g.Result, &g.Reason, &created, &updated, &ballot)
+	if err != nil {
+		return nil, err
+	}
+	g.CreatedAt, g.UpdatedAt = fromNanos(created), fromNanos(updated)
+	if ballot.Valid {
+		g.Ballot = &models.Ballot{}
+		if err := jsonpb.UnmarshalString(ballot.String, g.Ballot); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// updateRow saves the result of g and the time it changed.
+func updateRow(ctx context.Context, tx *sql.Tx, g *models.Game) error {
+	updated, err := nanos(g.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE games SET result = $1, reason = $2, updated_at = $3 WHERE id = $4`,
+		g.Result, g.Reason, updated, g.Id)
+	return err
+}
+
+func insertEvent(ctx context.Context, tx *sql.Tx, e *models.MoveEvent) error {
+	move, err := (&jsonpb.Marshaler{}).MarshalToString(e.Move)
+	if err != nil {
+		return err
+	}
+	created, err := nanos(e.CreatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO moves (game, seq, notation, move, player, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		e.Game, e.Seq, check.Notation(e.Move), move, e.Player, created)
+	return err
+}
+
+// queryEvents calls fn with the moves of the game id after the one numbered
+// after, in the order they were played.
+func queryEvents(ctx context.Context, q querier, id string, after int64, fn func(*models.MoveEvent) error) error {
+	rows, err := q.QueryContext(ctx, `SELECT seq, move, player, created_at FROM moves
+		WHERE game = $1 AND seq > $2 ORDER BY seq`, id, after)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var events []*models.MoveEvent
+	for rows.Next() {
+		e := &models.MoveEvent{Game: id, Move: &models.Move{}}
+		var move string
+		var created sql.NullInt64
+		if err := rows.Scan(&e.Seq, &move, &e.Player, &created); err != nil {
+			return err
+		}
+		if err := jsonpb.UnmarshalString(move, e.Move); err != nil {
+			return err
+		}
+		e.CreatedAt = fromNanos(created)
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// The rows are read before calling fn, which may query again on the one
+	// connection SQLite has.
+	for _, e := range events {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRows is load for SQL stores.
+func loadRows(ctx context.Context, tx *sql.Tx, g *models.Game) (play *check.Game, last, n int64, err error) {
+	var board string
+	err = tx.QueryRowContext(ctx, `SELECT seq, board FROM snapshots
+		WHERE game = $1 ORDER BY seq DESC LIMIT 1`, g.Id).Scan(&last, &board)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		play, err = start(g)
+	case err == nil:
+		b := &models.Board{}
+		err = jsonpb.UnmarshalString(board, b)
+		play = check.NewGameFrom(check.Restore(b))
+	}
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	n = last
+	err = queryEvents(ctx, tx, g.Id, last, func(e *models.MoveEvent) error {
+		n = e.Seq
+		return play.Apply(e.Move)
+	})
+	if err == nil {
+		err = fixBallot(g, play, last)
+	}
+	return play, last, n, err
+}
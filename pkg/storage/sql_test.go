@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gernest/8x8/pkg/models"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "8x8.db")
+	for i := 0; i < 2; i++ {
+		s, err := OpenSQL(ctx, "sqlite", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		if err := s.DB.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		s.DB.Close()
+		if n != len(migrations) {
+			t.Errorf("open %d: expected %d migrations got %d", i+1, len(migrations), n)
+		}
+	}
+}
+
+func TestSQLSnapshots(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQL(ctx, "sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.DB.Close()
+	s.SnapshotInterval = 2
+	games := s.Games()
+	g := &models.Game{}
+	if err := games.Create(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{"11-15", "23-19", "8-11", "22-17", "9-13"} {
+		if _, err := games.AppendMove(ctx, g.Id, mv(t, m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := s.DB.Query(`SELECT seq FROM snapshots WHERE game = $1 ORDER BY seq`, g.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for rows.Next() {
+		var n int64
+		rows.Scan(&n)
+		got = append(got, n)
+	}
+	rows.Close()
+	if !reflect.DeepEqual(got, []int64{2, 4}) {
+		t.Errorf("expected snapshots after moves 2 and 4 got %v", got)
+	}
+	var notation string
+	s.DB.QueryRow(`SELECT notation FROM moves WHERE game = $1 AND seq = 5`, g.Id).Scan(&notation)
+	if notation != "9-13" {
+		t.Errorf("expected the notation of the move to be stored got %q", notation)
+	}
+}
+
+func TestSQLUserReadError(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQL(ctx, "sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.DB.Close()
+	_, err = s.DB.Exec(`INSERT INTO users (email, name, picture, created_at) VALUES ('a@8x8.co.tz', 'a', '', 'yesterday')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.User().Create(ctx, &models.User{Name: "a", Email: "a@8x8.co.tz"}); err == nil {
+		t.Error("expected the profile that cannot be read to be kept")
+	}
+}
+
+func TestSQLWalkPages(t *testing.T) {
+	s, err := OpenSQL(context.Background(), "sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.DB.Close()
+	defer func(n int) { walkPage = n }(walkPage)
+	walkPage = 2
+	testWalk(t, s)
+}
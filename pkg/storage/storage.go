@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"io"
 	"strings"
 
 	"github.com/dgraph-io/badger/v3"
@@ -13,6 +14,7 @@ var ErrNotFound = errors.New("Not found")
 
 type Store interface {
 	User() User
+	Games() Games
 }
 
 type storeKey struct{}
@@ -37,8 +39,36 @@ func key(parts ...string) []byte {
 
 type DefaultStore struct {
 	DB *badger.DB
+	// SnapshotInterval is the number of moves between the snapshots of the
+	// position of a game, DefaultSnapshotInterval when zero.
+	SnapshotInterval int
 }
 
 func (d *DefaultStore) User() User {
 	return &badgerUSR{db: d.DB}
 }
+
+func (d *DefaultStore) Games() Games {
+	return &badgerGames{db: d.DB, every: int64(d.SnapshotInterval)}
+}
+
+// Open opens the store selected by driver: badger for a badger database in
+// the directory source, otherwise the name of a database/sql driver such as
+// sqlite, with source the database to open. The returned Closer releases the
+// database.
+func Open(ctx context.Context, driver, source string) (Store, io.Closer, error) {
+	if driver == "badger" {
+		// Badger logs what it does when opening at INFO, which would end up
+		// in the output of every command.
+		db, err := badger.Open(badger.DefaultOptions(source).WithLogger(nil))
+		if err != nil {
+			return nil, nil, err
+		}
+		return &DefaultStore{DB: db}, db, nil
+	}
+	s, err := OpenSQL(ctx, driver, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, s.DB, nil
+}
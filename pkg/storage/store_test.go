@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/gernest/8x8/pkg/models"
+)
+
+// conformance runs the tests every Store must pass against stores returned by
+// open, a new empty one for each test.
+func conformance(t *testing.T, open func(t *testing.T) Store) {
+	for _, c := range []struct {
+		name string
+		test func(*testing.T, Store)
+	}{
+		{"users", testUsers},
+		{"games", testGames},
+		{"ballot", testBallot},
+		{"move log", testMoveLog},
+		{"list games", testListGames},
+		{"walk games", testWalk},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, open(t))
+		})
+	}
+}
+
+func TestBadgerStore(t *testing.T) {
+	conformance(t, func(t *testing.T) Store {
+		return &DefaultStore{DB: openDB(t), SnapshotInterval: 4}
+	})
+}
+
+func TestSQLStore(t *testing.T) {
+	conformance(t, func(t *testing.T) Store {
+		s, err := OpenSQL(context.Background(), "sqlite", filepath.Join(t.TempDir(), "8x8.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.DB.Close() })
+		s.SnapshotInterval = 4
+		return s
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	conformance(t, func(*testing.T) Store {
+		return NewMemoryStore()
+	})
+}
+
+func testUsers(t *testing.T, store Store) {
+	ctx := context.Background()
+	users := store.User()
+	for _, email := range []string{"b@8x8.co.tz", "a@8x8.co.tz", "c@8x8.co.tz"} {
+		if err := users.Create(ctx, &models.User{Name: email[:1], Email: email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	usr, err := users.Get(ctx, "a@8x8.co.tz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usr.Name != "a" || usr.CreatedAt == nil || usr.UpdatedAt != nil {
+		t.Errorf("unexpected user %v", usr)
+	}
+	// Saving the same profile again changes nothing.
+	if err := users.Create(ctx, &models.User{Name: "a", Email: "a@8x8.co.tz"}); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := users.Get(ctx, "a@8x8.co.tz"); again.UpdatedAt != nil {
+		t.Errorf("expected no update got %v", again)
+	}
+	if err := users.Create(ctx, &models.User{Name: "Alice", Email: "a@8x8.co.tz"}); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := users.Get(ctx, "a@8x8.co.tz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "Alice" || updated.UpdatedAt == nil || updated.CreatedAt.AsTime() != usr.CreatedAt.AsTime() {
+		t.Errorf("expected the profile to be updated got %v", updated)
+	}
+	if _, err := users.Get(ctx, "d@8x8.co.tz"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found got %v", err)
+	}
+	ls, err := users.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, u := range ls {
+		got = append(got, u.Email)
+	}
+	if len(got) != 3 || got[0] != "a@8x8.co.tz" || got[2] != "c@8x8.co.tz" {
+		t.Errorf("expected every user by email got %v", got)
+	}
+}
+
+func TestBadgerUserReadError(t *testing.T) {
+	db := openDB(t)
+	email := "a@8x8.co.tz"
+	err := db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key(profile, email), []byte{0xff})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := (&DefaultStore{DB: db}).User()
+	if err := users.Create(context.Background(), &models.User{Name: "a", Email: email}); err == nil {
+		t.Error("expected the profile that cannot be read to be kept")
+	}
+}
@@ -20,23 +20,14 @@ type badgerUSR struct {
 
 func (b *badgerUSR) Create(ctx context.Context, usr *models.User) error {
 	old, err := b.Get(ctx, usr.Email)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if !merge(usr, old) {
+		return nil
+	}
 	return b.db.Update(func(txn *badger.Txn) error {
-		k := key(profile, usr.Email)
-		if err == nil {
-			if proto.Equal(usr, &models.User{
-				Name:    old.Name,
-				Email:   old.Email,
-				Picture: old.Picture,
-			}) {
-				return nil
-			}
-			usr.CreatedAt = old.CreatedAt
-			usr.UpdatedAt = ptypes.TimestampNow()
-		} else {
-			usr.CreatedAt = ptypes.TimestampNow()
-		}
-		b, _ := proto.Marshal(usr)
-		return txn.Set(k, b)
+		return set(txn, key(profile, usr.Email), usr)
 	})
 }
 
@@ -62,9 +53,9 @@ func (b *badgerUSR) List(ctx context.Context) (ls []*models.User, err error) {
 	err = b.db.View(func(txn *badger.Txn) error {
 		it := txn.NewIterator(badger.DefaultIteratorOptions)
 		defer it.Close()
-		prefix := []byte(profile)
+		prefix := key(profile, "")
 		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			return it.Item().Value(func(v []byte) error {
+			err := it.Item().Value(func(v []byte) error {
 				m := &models.User{}
 				if err := proto.Unmarshal(v, m); err != nil {
 					return err
@@ -72,8 +63,30 @@ func (b *badgerUSR) List(ctx context.Context) (ls []*models.User, err error) {
 				ls = append(ls, m)
 				return nil
 			})
+			if err != nil {
+				return err
+			}
 		}
 		return nil
 	})
 	return
 }
+
+// merge prepares usr to replace old, nil for a new user, keeping the time the
+// user was created. It reports whether there is anything to save.
+func merge(usr, old *models.User) bool {
+	if old == nil {
+		usr.CreatedAt = ptypes.TimestampNow()
+		return true
+	}
+	if proto.Equal(usr, &models.User{
+		Name:    old.Name,
+		Email:   old.Email,
+		Picture: old.Picture,
+	}) {
+		return false
+	}
+	usr.CreatedAt = old.CreatedAt
+	usr.UpdatedAt = ptypes.TimestampNow()
+	return true
+}
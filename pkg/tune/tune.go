@@ -0,0 +1,197 @@
+// Package tune fits the weights of the position evaluation to the results of
+// finished games, the way Texel tuning does: each quiet position of a game is
+// expected to predict the game's result through a logistic curve of its
+// score, and the weights are adjusted until the mean squared error of those
+// predictions stops improving.
+package tune
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/pdn"
+)
+
+// ErrNoSamples is returned when the games give no positions to tune on.
+var ErrNoSamples = errors.New("tune: no positions to tune on")
+
+// Sample is a position of a finished game.
+type Sample struct {
+	Terms check.Terms
+	// Result is what the player to move scored in the game, 1 for a win,
+	// 0.5 for a draw and 0 for a loss.
+	Result float64
+}
+
+// Samples replays the finished games and returns their quiet positions, those
+// where no capture is pending, leaving out the first skip moves of each game.
+// Games without a result are ignored.
+func Samples(games []*pdn.Game, skip int) ([]Sample, error) {
+	var samples []Sample
+	for i, g := range games {
+		if g.Result == check.Ongoing {
+			continue
+		}
+		end, err := g.Replay()
+		if err != nil {
+			return nil, fmt.Errorf("tune: game %d: %w", i+1, err)
+		}
+		game, err := start(end)
+		if err != nil {
+			return nil, fmt.Errorf("tune: game %d: %w", i+1, err)
+		}
+		for ply, m := range end.History() {
+			if ply >= skip && quiet(game) {
+				b := game.Board()
+				samples = append(samples, Sample{
+					Terms:  b.Features(),
+					Result: score(g.Result, game.Turn()),
+				})
+			}
+			if err := game.Apply(m); err != nil {
+				return nil, fmt.Errorf("tune: game %d: %w", i+1, err)
+			}
+		}
+	}
+	if len(samples) == 0 {
+		return nil, ErrNoSamples
+	}
+	return samples, nil
+}
+
+// start returns a new game from the position g started from.
+func start(g *check.Game) (*check.Game, error) {
+	if fen := g.Setup(); fen != "" {
+		b, err := g.Variant().ParseFEN(fen)
+		if err != nil {
+			return nil, err
+		}
+		return check.NewGameFrom(b), nil
+	}
+	return g.Variant().NewGame(), nil
+}
+
+// quiet reports whether the player to move in g has no capture to make.
+func quiet(g *check.Game) bool {
+	for _, m := range g.LegalMoves() {
+		if len(m.Captures) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func score(r check.Result, p check.Player) float64 {
+	switch {
+	case r == check.Draw:
+		return 0.5
+	case (r == check.WhiteWins) == (p == check.White):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// predict returns the expected result for a player whose position scores s,
+// s being in hundredths of a man. k scales the curve.
+func predict(s int, k float64) float64 {
+	return 1 / (1 + math.Pow(10, -k*float64(s)/400))
+}
+
+// Error returns the mean squared error of the results of samples as predicted
+// from their scores under w.
+func Error(samples []Sample, w *check.Weights, k float64) float64 {
+	sum := 0.0
+	for _, s := range samples {
+		d := s.Result - predict(w.Evaluate(s.Terms), k)
+		sum += d * d
+	}
+	return sum / float64(len(samples))
+}
+
+// Scale returns the k for which w predicts the results of samples best.
+func Scale(samples []Sample, w *check.Weights) float64 {
+	// The error has a single minimum in k, a golden section search finds it.
+	lo, hi := 0.0, 10.0
+	const phi = 0.6180339887498949
+	a, b := hi-phi*(hi-lo), lo+phi*(hi-lo)
+	ea, eb := Error(samples, w, a), Error(samples, w, b)
+	for hi-lo > 1e-4 {
+		if ea < eb {
+			hi, b, eb = b, a, ea
+			a = hi - phi*(hi-lo)
+			ea = Error(samples, w, a)
+		} else {
+			lo, a, ea = a, b, eb
+			b = lo + phi*(hi-lo)
+			eb = Error(samples, w, b)
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// Options control Tune.
+type Options struct {
+	// Iterations is the most passes over the weights, 0 for no limit.
+	Iterations int
+	// Fixed are features whose weight is kept. Material is always kept, it
+	// gives the scores their scale.
+	Fixed []check.Feature
+	// Progress, when set, is called with the error after every pass.
+	Progress func(pass int, err float64)
+}
+
+// Report tells how Tune went.
+type Report struct {
+	Samples int
+	// K is the scale of the logistic curve fitted to the starting weights.
+	K      float64
+	Before float64
+	After  float64
+	Passes int
+}
+
+// Tune returns the weights, starting from w, that predict the results of
+// samples best. Each pass moves every weight up or down by one while that
+// lowers the error, until a pass changes nothing.
+func Tune(samples []Sample, w check.Weights, opts Options) (check.Weights, Report) {
+	fixed := make(map[check.Feature]bool)
+	fixed[check.Material] = true
+	for _, f := range opts.Fixed {
+		fixed[f] = true
+	}
+	k := Scale(samples, &w)
+	r := Report{Samples: len(samples), K: k, Before: Error(samples, &w, k)}
+	best := r.Before
+	for opts.Iterations == 0 || r.Passes < opts.Iterations {
+		r.Passes++
+		improved := false
+		for f := range w {
+			if fixed[check.Feature(f)] {
+				continue
+			}
+			for _, step := range []int{1, -1} {
+				for {
+					w[f] += step
+					if e := Error(samples, &w, k); e < best {
+						best = e
+						improved = true
+						continue
+					}
+					w[f] -= step
+					break
+				}
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(r.Passes, best)
+		}
+		if !improved {
+			break
+		}
+	}
+	r.After = best
+	return w, r
+}
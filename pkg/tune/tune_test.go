@@ -0,0 +1,86 @@
+package tune
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/gernest/8x8/pkg/bot"
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/pdn"
+)
+
+// corpus returns games between bots of the ladder, drawn when they run long.
+func corpus(t *testing.T, n int) []*pdn.Game {
+	t.Helper()
+	r := rand.New(rand.NewSource(1))
+	var games []*pdn.Game
+	for i := 0; i < n; i++ {
+		black := bot.Ladder[r.Intn(len(bot.Ladder)-1)]
+		white := bot.Ladder[r.Intn(len(bot.Ladder)-1)]
+		g := check.NewGame()
+		for ply := 0; ply < 150 && !g.Over(); ply++ {
+			b := black
+			if g.Turn() == check.White {
+				b = white
+			}
+			if _, err := b.Play(g, r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !g.Over() {
+			g.AgreeDraw()
+		}
+		games = append(games, pdn.Record(g))
+	}
+	return games
+}
+
+func TestTune(t *testing.T) {
+	games := corpus(t, 40)
+	games = append(games, &pdn.Game{})
+	samples, err := Samples(games, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := check.English.DefaultWeights()
+	tuned, r := Tune(samples, w, Options{Iterations: 10, Fixed: []check.Feature{check.Tempo}})
+	if r.Samples != len(samples) || r.K <= 0 {
+		t.Errorf("unexpected report %+v", r)
+	}
+	if r.After >= r.Before {
+		t.Errorf("expected the error to go down from %f got %f", r.Before, r.After)
+	}
+	if e := Error(samples, &tuned, r.K); e != r.After {
+		t.Errorf("expected the tuned weights to have error %f got %f", r.After, e)
+	}
+	if tuned[check.Material] != w[check.Material] || tuned[check.Tempo] != w[check.Tempo] {
+		t.Errorf("expected fixed weights to be kept got %v", tuned)
+	}
+}
+
+func TestSamples(t *testing.T) {
+	// Black takes 10x19x26 and is crowned, White resigns.
+	g, err := pdn.ParseString(`[FEN "B:W14,15,23,32:B10"] 1. 10x19x26 32-27 2. 26-31 27-23 0-2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, err := Samples(g, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first position is not quiet, the rest alternate between White who
+	// lost and Black who won.
+	want := []float64{0, 1, 0}
+	if len(samples) != len(want) {
+		t.Fatalf("expected %d samples got %d", len(want), len(samples))
+	}
+	for i, s := range samples {
+		if s.Result != want[i] {
+			t.Errorf("sample %d: expected %v got %v", i, want[i], s.Result)
+		}
+	}
+	if _, err := Samples(nil, 0); !errors.Is(err, ErrNoSamples) {
+		t.Errorf("expected no samples got %v", err)
+	}
+}
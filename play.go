@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gernest/8x8/pkg/bot"
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/urfave/cli"
+)
+
+var playCommand = cli.Command{
+	Name:  "play",
+	Usage: "plays a game against a bot in the terminal",
+	Flags: []cli.Flag{
+		variantFlag,
+		cli.StringFlag{
+			Name:  "bot",
+			Value: bot.Ladder[0].Name,
+			Usage: "bot to play against, see --list",
+		},
+		cli.StringFlag{
+			Name:  "color",
+			Usage: "colour you play, black or white, the side that moves first when empty",
+		},
+		cli.Int64Flag{
+			Name:  "seed",
+			Usage: "seed of the bot's random choices, the current time when 0",
+		},
+		cli.StringFlag{
+			Name:  "ballot",
+			Usage: "number of the ballot to start with, or random to deal one",
+		},
+		cli.StringFlag{
+			Name:  "ballots",
+			Usage: "file of approved ballots, one per line such as 11-15 23-19 8-11 Old Fourteenth",
+		},
+		cli.BoolFlag{
+			Name:  "pair",
+			Usage: "play the ballot again with colours swapped after the game, as the second of a pair",
+		},
+		cli.StringFlag{
+			Name:  "book",
+			Usage: "opening book to name openings and give hints from",
+		},
+		cli.BoolFlag{
+			Name:  "list",
+			Usage: "print the bots and exit",
+		},
+	},
+	Action: play,
+}
+
+func play(ctx *cli.Context) error {
+	w := ctx.App.Writer
+	if ctx.Bool("list") {
+		for _, b := range bot.Ladder {
+			fmt.Fprintf(w, "%-8s %5d  %s\n", b.Name, b.Rating, b.Description)
+		}
+		return nil
+	}
+	v := check.LookupVariant(ctx.String("variant"))
+	if v == nil {
+		return fmt.Errorf("unknown variant %q", ctx.String("variant"))
+	}
+	human := v.First
+	switch ctx.String("color") {
+	case "":
+	case "black":
+		human = check.Black
+	case "white":
+		human = check.White
+	default:
+		return fmt.Errorf("unknown colour %q", ctx.String("color"))
+	}
+	seed := ctx.Int64("seed")
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	g, err := newGame(ctx, v, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return err
+	}
+	b, ok := g.Ballot()
+	if ctx.Bool("pair") && !ok {
+		return fmt.Errorf("%w: a pair is played from a ballot, use --ballot", check.ErrBallot)
+	}
+	var bk *check.Book
+	if name := ctx.String("book"); name != "" {
+		if bk, err = loadBook(name); err != nil {
+			return err
+		}
+	}
+	in := bufio.NewScanner(os.Stdin)
+	if err := playMatch(ctx, in, g, human, seed, bk); err != nil || !ctx.Bool("pair") {
+		return err
+	}
+	fmt.Fprintln(w)
+	if g, err = v.NewBallotGame(b); err != nil {
+		return err
+	}
+	return playMatch(ctx, in, g, !human, seed, bk)
+}
+
+// playMatch plays g against the bot chosen by the flags, with the human
+// playing as human, and prints the record.
+func playMatch(ctx *cli.Context, in *bufio.Scanner, g *check.Game, human check.Player, seed int64, bk *check.Book) error {
+	w := ctx.App.Writer
+	v := g.Variant()
+	m, err := bot.NewMatchFrom(g, ctx.String("bot"), !human, seed)
+	if err != nil {
+		return err
+	}
+	if b, ok := g.Ballot(); ok {
+		fmt.Fprintln(w, strings.TrimSpace(fmt.Sprintf("ballot %d: %s %s", b.Number, b, b.Name)))
+	}
+	var opening string
+	fmt.Fprintf(w, "playing %s (%d) as %v, enter moves like 11-15 or 22x15, resign to stop\n",
+		m.Bot.Name, m.Bot.Rating, human)
+	if bk != nil {
+		fmt.Fprintln(w, "hint lists the book moves")
+	}
+	for !m.Game.Over() {
+		if m.Game.Turn() == m.Side {
+			mv, err := m.Reply()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s plays %s\n", m.Bot.Name, check.Notation(mv))
+			continue
+		}
+		if bk != nil {
+			if name := bk.Opening(m.Game); name != opening {
+				opening = name
+				fmt.Fprintf(w, "opening: %s\n", name)
+			}
+		}
+		diagram(w, v, m.Game.Board())
+		fmt.Fprintf(w, "%v to move: ", human)
+		if !in.Scan() {
+			if err := in.Err(); err != nil {
+				return err
+			}
+			return io.EOF
+		}
+		switch text := strings.TrimSpace(in.Text()); {
+		case text == "resign":
+			if err := m.Game.Resign(human); err != nil {
+				return err
+			}
+		case text == "hint" && bk != nil:
+			fmt.Fprintln(w, bookMoves(bk, m.Game))
+		default:
+			mv, err := check.ParseMove(text)
+			if err == nil {
+				err = m.Game.Apply(mv)
+			}
+			if err != nil {
+				fmt.Fprintln(w, err)
+			}
+		}
+	}
+	diagram(w, v, m.Game.Board())
+	fmt.Fprintf(w, "%v (%v)\n\n", m.Game.Result(), m.Game.Reason())
+	fmt.Fprint(w, m.Record("").String())
+	return nil
+}
+
+// newGame starts a game of v, with the ballot given by the ballot flags.
+func newGame(ctx *cli.Context, v *check.Variant, r *rand.Rand) (*check.Game, error) {
+	choice := ctx.String("ballot")
+	if choice == "" {
+		return v.NewGame(), nil
+	}
+	// Not every opening is approved, the list is published with the rules of
+	// a tournament.
+	name := ctx.String("ballots")
+	if name == "" {
+		return nil, fmt.Errorf("%w: no list of approved ballots, use --ballots", check.ErrBallot)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	ballots, err := check.ReadBallots(v, f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(ballots) == 0 {
+		return nil, fmt.Errorf("%w: empty list", check.ErrBallot)
+	}
+	var b check.Ballot
+	if choice == "random" {
+		b = check.Deal(ballots, r)
+	} else {
+		n, err := strconv.Atoi(choice)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q", check.ErrBallot, choice)
+		}
+		if b, err = check.FindBallot(ballots, n); err != nil {
+			return nil, err
+		}
+	}
+	return v.NewBallotGame(b)
+}
+
+// diagram draws b with Black's men as b, White's as w and kings in capitals.
+func diagram(w io.Writer, v *check.Variant, b *check.Board) {
+	grid := make([][]byte, v.Size)
+	for r := range grid {
+		grid[r] = []byte(strings.Repeat(" ", v.Size))
+	}
+	for sq := 1; sq <= v.Squares(); sq++ {
+		r, c := v.Coordinates(int32(sq))
+		grid[r][c] = '.'
+	}
+	for _, p := range b.UncapturedPieces {
+		r, c := v.Coordinates(p.Position)
+		ch := byte('w')
+		if check.Player(p.Player) == check.Black {
+			ch = 'b'
+		}
+		if p.King {
+			ch -= 'a' - 'A'
+		}
+		grid[r][c] = ch
+	}
+	for _, row := range grid {
+		fmt.Fprintf(w, "  %s\n", strings.Join(strings.Split(string(row), ""), " "))
+	}
+}
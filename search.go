@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/engine"
+	"github.com/gernest/8x8/pkg/models"
+	"github.com/urfave/cli"
+)
+
+var searchCommand = cli.Command{
+	Name:  "search",
+	Usage: "finds the best move of a position",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "fen",
+			Usage: "position to search, the starting position when empty",
+		},
+		variantFlag,
+		weightsFlag,
+		cli.StringFlag{
+			Name:  "tablebase",
+			Usage: "endgame tablebase file to look positions up in",
+		},
+		cli.IntFlag{
+			Name:  "depth",
+			Usage: "number of moves to look ahead, 0 for no limit",
+		},
+		cli.DurationFlag{
+			Name:  "time",
+			Value: 5 * time.Second,
+			Usage: "how long to search, 0 for no limit",
+		},
+	},
+	Action: search,
+}
+
+func search(ctx *cli.Context) error {
+	b, err := board(ctx)
+	if err != nil {
+		return err
+	}
+	w, err := weights(ctx, b)
+	if err != nil {
+		return err
+	}
+	e := engine.New(engine.Limits{
+		Depth: ctx.Int("depth"),
+		Time:  ctx.Duration("time"),
+	})
+	e.Weights = &w
+	if name := ctx.String("tablebase"); name != "" {
+		if e.Tablebase, err = loadTablebase(name); err != nil {
+			return err
+		}
+	}
+	e.Info = func(r engine.Result) {
+		fmt.Fprintf(ctx.App.Writer, "depth %d score %d nodes %d time %v pv %s\n",
+			r.Depth, r.Score, r.Nodes, r.Time.Round(time.Millisecond), line(r.PV))
+	}
+	r, err := e.Search(context.Background(), b)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.App.Writer, "bestmove %s\n", check.Notation(r.Move))
+	return nil
+}
+
+func line(moves []*models.Move) string {
+	ls := make([]string, len(moves))
+	for i, m := range moves {
+		ls[i] = check.Notation(m)
+	}
+	return strings.Join(ls, " ")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/urfave/cli"
+)
+
+var tablebaseCommand = cli.Command{
+	Name:  "tablebase",
+	Usage: "generates and probes English draughts endgame tablebases",
+	Subcommands: []cli.Command{
+		{
+			Name:  "generate",
+			Usage: "works out every position with up to a number of pieces",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "pieces",
+					Value: 3,
+					Usage: fmt.Sprintf("most pieces on the board, up to %d", check.MaxTablebasePieces),
+				},
+				cli.StringFlag{
+					Name:  "out",
+					Value: "tablebase.gz",
+					Usage: "file to write the tablebase to",
+				},
+			},
+			Action: generateTablebase,
+		},
+		{
+			Name:  "probe",
+			Usage: "prints the outcome of a position with best play",
+			Flags: []cli.Flag{
+				tablebaseFlag,
+				cli.StringFlag{
+					Name:  "fen",
+					Usage: "position to look up",
+				},
+			},
+			Action: probeTablebase,
+		},
+	},
+}
+
+var tablebaseFlag = cli.StringFlag{
+	Name:  "tablebase",
+	Value: "tablebase.gz",
+	Usage: "tablebase file written by tablebase generate",
+}
+
+func generateTablebase(ctx *cli.Context) error {
+	start := time.Now()
+	tb, err := check.GenerateTablebase(ctx.Int("pieces"), func(pieces int) {
+		fmt.Fprintf(ctx.App.Writer, "%d pieces done in %v\n", pieces, time.Since(start).Round(time.Millisecond))
+	})
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(ctx.String("out"))
+	if err != nil {
+		return err
+	}
+	n, err := tb.WriteTo(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.App.Writer, "wrote %d bytes to %s\n", n, ctx.String("out"))
+	return nil
+}
+
+// loadTablebase reads the tablebase file called name.
+func loadTablebase(name string) (*check.Tablebase, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tb, err := check.ReadTablebase(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return tb, nil
+}
+
+func probeTablebase(ctx *cli.Context) error {
+	tb, err := loadTablebase(ctx.String("tablebase"))
+	if err != nil {
+		return err
+	}
+	b, err := check.ParseFEN(ctx.String("fen"))
+	if err != nil {
+		return err
+	}
+	o, d, ok := tb.Probe(b)
+	if !ok {
+		return fmt.Errorf("more than %d pieces", tb.Pieces)
+	}
+	fmt.Fprintf(ctx.App.Writer, "%v to move: %v in %d plies\n", check.Player(b.PlayertTurn), o, d)
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gernest/8x8/pkg/check"
+	"github.com/gernest/8x8/pkg/pdn"
+	"github.com/gernest/8x8/pkg/tune"
+	"github.com/urfave/cli"
+)
+
+var tuneCommand = cli.Command{
+	Name:      "tune",
+	Usage:     "fits the evaluation weights to the results of finished games",
+	ArgsUsage: "games.pdn...",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the tuned weights to, required",
+		},
+		cli.StringFlag{
+			Name:  "weights",
+			Usage: "weights to start from, the defaults of the variant when empty",
+		},
+		cli.IntFlag{
+			Name:  "skip",
+			Value: 8,
+			Usage: "number of opening moves of each game to leave out",
+		},
+		cli.IntFlag{
+			Name:  "iterations",
+			Usage: "most passes over the weights, 0 for no limit",
+		},
+		cli.StringSliceFlag{
+			Name:  "fixed",
+			Usage: "feature whose weight is kept, can be repeated",
+		},
+	},
+	Action: tuneWeights,
+}
+
+func tuneWeights(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return errors.New("no game files given")
+	}
+	if ctx.String("out") == "" {
+		return errors.New("no --out file given for the weights")
+	}
+	var games []*pdn.Game
+	for _, name := range ctx.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		gs, err := pdn.Parse(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		games = append(games, gs...)
+	}
+	v, err := corpusVariant(games)
+	if err != nil {
+		return err
+	}
+	w := v.DefaultWeights()
+	if name := ctx.String("weights"); name != "" {
+		if w, err = v.LoadWeights(name); err != nil {
+			return err
+		}
+	}
+	var fixed []check.Feature
+	for _, name := range ctx.StringSlice("fixed") {
+		f, ok := check.ParseFeature(name)
+		if !ok {
+			return fmt.Errorf("unknown feature %q", name)
+		}
+		fixed = append(fixed, f)
+	}
+	samples, err := tune.Samples(games, ctx.Int("skip"))
+	if err != nil {
+		return err
+	}
+	out := ctx.App.Writer
+	fmt.Fprintf(out, "%d games, %d positions\n", len(games), len(samples))
+	tuned, r := tune.Tune(samples, w, tune.Options{
+		Iterations: ctx.Int("iterations"),
+		Fixed:      fixed,
+		Progress: func(pass int, err float64) {
+			fmt.Fprintf(out, "pass %d error %.6f\n", pass, err)
+		},
+	})
+	fmt.Fprintf(out, "k %.4f error before %.6f after %.6f\n", r.K, r.Before, r.After)
+	f, err := os.Create(ctx.String("out"))
+	if err != nil {
+		return err
+	}
+	if err := tuned.WriteWeights(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// corpusVariant returns the variant all games are played in.
+func corpusVariant(games []*pdn.Game) (*check.Variant, error) {
+	var v *check.Variant
+	for i, g := range games {
+		gv, err := g.Variant()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+		if v != nil && gv != v {
+			return nil, fmt.Errorf("game %d: %s among %s games", i+1, gv.Name, v.Name)
+		}
+		v = gv
+	}
+	if v == nil {
+		return nil, tune.ErrNoSamples
+	}
+	return v, nil
+}